@@ -19,58 +19,59 @@ type AccountGetOptions struct {
 
 // AccountGetOutput is the returned struct from GetTariffCharges.
 type AccountGetOutput struct {
-	Number     string `json:"number"`
-	Properties []struct {
-		ID                     int                      `json:"id"`
-		MovedInAt              time.Time                `json:"moved_in_at"`
-		MovedOutAt             *time.Time               `json:"moved_out_at"`
-		AddressLine1           string                   `json:"address_line_1"`
-		AddressLine2           string                   `json:"address_line_2"`
-		AddressLine3           string                   `json:"address_line_3"`
-		Town                   string                   `json:"town"`
-		County                 string                   `json:"county"`
-		Postcode               string                   `json:"postcode"`
-		ElectricityMeterPoints []ElectricityMeterPoints `json:"electricity_meter_points"`
-		GasMeterPoints         []GasMeterPoints         `json:"gas_meter_points"`
-	} `json:"properties"`
+	Number     string     `json:"number"`
+	Properties []Property `json:"properties"`
+}
+
+// Property is a property on an account. A nil MovedOutAt means the customer still lives there.
+type Property struct {
+	ID                     int                      `json:"id"`
+	MovedInAt              time.Time                `json:"moved_in_at"`
+	MovedOutAt             *time.Time               `json:"moved_out_at"`
+	AddressLine1           string                   `json:"address_line_1"`
+	AddressLine2           string                   `json:"address_line_2"`
+	AddressLine3           string                   `json:"address_line_3"`
+	Town                   string                   `json:"town"`
+	County                 string                   `json:"county"`
+	Postcode               string                   `json:"postcode"`
+	ElectricityMeterPoints []ElectricityMeterPoints `json:"electricity_meter_points"`
+	GasMeterPoints         []GasMeterPoints         `json:"gas_meter_points"`
 }
 
 type ElectricityMeterPoints struct {
-	MPAN                string `json:"mpan"`
-	ProfileClass        int    `json:"profile_class"`
-	ConsumptionStandard int    `json:"consumption_standard"`
-	Meters              []struct {
-		SerialNumber string `json:"serial_number"`
-		Registers    []struct {
-			Identifier           string `json:"identifier"`
-			Rate                 string `json:"rate"`
-			IsSettlementRegister bool   `json:"is_settlement_register"`
-		} `json:"registers"`
-	} `json:"meters"`
-	Agreements []struct {
-		TariffCode string     `json:"tariff_code"`
-		ValidFrom  time.Time  `json:"valid_from"`
-		ValidTo    *time.Time `json:"valid_to"`
-	} `json:"agreements"`
+	MPAN                string      `json:"mpan"`
+	ProfileClass        int         `json:"profile_class"`
+	ConsumptionStandard int         `json:"consumption_standard"`
+	Meters              []Meter     `json:"meters"`
+	Agreements          []Agreement `json:"agreements"`
 }
 
 type GasMeterPoints struct {
-	MPRN                string `json:"mprn"`
-	ProfileClass        int    `json:"profile_class"`
-	ConsumptionStandard int    `json:"consumption_standard"`
-	Meters              []struct {
-		SerialNumber string `json:"serial_number"`
-		Registers    []struct {
-			Identifier           string `json:"identifier"`
-			Rate                 string `json:"rate"`
-			IsSettlementRegister bool   `json:"is_settlement_register"`
-		} `json:"registers"`
-	} `json:"meters"`
-	Agreements []struct {
-		TariffCode string     `json:"tariff_code"`
-		ValidFrom  time.Time  `json:"valid_from"`
-		ValidTo    *time.Time `json:"valid_to"`
-	} `json:"agreements"`
+	MPRN                string      `json:"mprn"`
+	ProfileClass        int         `json:"profile_class"`
+	ConsumptionStandard int         `json:"consumption_standard"`
+	Meters              []Meter     `json:"meters"`
+	Agreements          []Agreement `json:"agreements"`
+}
+
+// Meter is a physical meter installed at a meter point.
+type Meter struct {
+	SerialNumber string          `json:"serial_number"`
+	Registers    []MeterRegister `json:"registers"`
+}
+
+// MeterRegister is a register of a meter, single rate meters have one and Economy 7 meters two.
+type MeterRegister struct {
+	Identifier           string `json:"identifier"`
+	Rate                 string `json:"rate"`
+	IsSettlementRegister bool   `json:"is_settlement_register"`
+}
+
+// Agreement is a tariff a meter point was supplied on. A nil ValidTo means the agreement is open ended.
+type Agreement struct {
+	TariffCode string     `json:"tariff_code"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidTo    *time.Time `json:"valid_to"`
 }
 
 // Get retrieves the details of an account.
@@ -90,7 +91,14 @@ func (s *AccountService) GetWithContext(ctx context.Context, options *AccountGet
 	}
 
 	res := AccountGetOutput{}
-	if err := s.client.sendRequest(req, true, &res); err != nil {
+	op := Operation{
+		Service:      "Account",
+		Method:       "Get",
+		HTTPMethod:   http.MethodGet,
+		PathTemplate: "/v1/accounts/{account_number}/",
+		AuthRequired: true,
+	}
+	if err := s.client.sendRequest(op, req, &res); err != nil {
 		return nil, err
 	}
 
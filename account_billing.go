@@ -0,0 +1,438 @@
+package octopusenergy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AccountBalanceGetOptions is the options for GetBalance.
+type AccountBalanceGetOptions struct {
+	// The octopus account number to be retrieved.
+	AccountNumber string
+}
+
+// AccountBalanceGetOutput is the returned struct from GetBalance.
+type AccountBalanceGetOutput struct {
+	// Account balance in pence, positive when the account is in credit.
+	Balance int `json:"balance"`
+}
+
+const accountBalanceQuery = `query AccountBalance($accountNumber: String!) {
+  account(accountNumber: $accountNumber) {
+    balance
+  }
+}`
+
+// GetBalance retrieves the current balance of an account.
+func (s *AccountService) GetBalance(options *AccountBalanceGetOptions) (*AccountBalanceGetOutput, error) {
+	return s.GetBalanceWithContext(context.Background(), options)
+}
+
+// GetBalanceWithContext same as GetBalance except it takes a Context.
+func (s *AccountService) GetBalanceWithContext(ctx context.Context, options *AccountBalanceGetOptions) (*AccountBalanceGetOutput, error) {
+	var res struct {
+		Account AccountBalanceGetOutput `json:"account"`
+	}
+
+	vars := map[string]interface{}{"accountNumber": options.AccountNumber}
+	if err := s.client.GraphQL.QueryWithContext(ctx, accountBalanceQuery, vars, &res); err != nil {
+		return nil, err
+	}
+	return &res.Account, nil
+}
+
+// AccountBillsListOptions is the options for ListBills.
+type AccountBillsListOptions struct {
+	// The octopus account number to be retrieved.
+	AccountNumber string
+
+	// Page size of returned results. Default is 20.
+	PageSize *int
+
+	// Pagination cursor to be returned on this request, the Next value of a previous output.
+	Page *string
+}
+
+// AccountBillsListOutput is the returned struct from ListBills. Next and Previous are page cursors
+// rather than links, they are blank when there is no further page.
+type AccountBillsListOutput struct {
+	Count    int
+	Next     string
+	Previous string
+	Results  []Bill
+}
+
+// Bill is a bill statement issued for an account. Amounts are in pence.
+type Bill struct {
+	ID         string
+	BillType   string
+	IssuedDate time.Time
+
+	// The period the bill covers.
+	FromDate time.Time
+	ToDate   time.Time
+
+	// Only populated for statements.
+	OpeningBalance int
+	ClosingBalance int
+	TotalCharges   BillTotal
+	TotalCredits   BillTotal
+}
+
+// BillTotal is a total with its tax breakdown, in pence.
+type BillTotal struct {
+	NetTotal   int `json:"netTotal"`
+	TaxTotal   int `json:"taxTotal"`
+	GrossTotal int `json:"grossTotal"`
+}
+
+const accountBillsQuery = `query AccountBills($accountNumber: String!, $first: Int, $after: String) {
+  account(accountNumber: $accountNumber) {
+    bills(first: $first, after: $after) {
+      totalCount
+      pageInfo {
+        hasNextPage
+        endCursor
+        hasPreviousPage
+        startCursor
+      }
+      edges {
+        node {
+          id
+          billType
+          issuedDate
+          fromDate
+          toDate
+          ... on StatementType {
+            openingBalance
+            closingBalance
+            totalCharges {
+              netTotal
+              taxTotal
+              grossTotal
+            }
+            totalCredits {
+              netTotal
+              taxTotal
+              grossTotal
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// ListBills return a list of bills issued for an account, most recent first. This endpoint is paginated,
+// it will return next and previous cursors if there is more data, you are responsible to request the next
+// page if required.
+func (s *AccountService) ListBills(options *AccountBillsListOptions) (*AccountBillsListOutput, error) {
+	return s.ListBillsWithContext(context.Background(), options)
+}
+
+// ListBillsWithContext same as ListBills except it takes a Context.
+func (s *AccountService) ListBillsWithContext(ctx context.Context, options *AccountBillsListOptions) (*AccountBillsListOutput, error) {
+	var res struct {
+		Account struct {
+			Bills struct {
+				graphQLConnection
+				Edges []struct {
+					Node struct {
+						ID             string     `json:"id"`
+						BillType       string     `json:"billType"`
+						IssuedDate     krakenDate `json:"issuedDate"`
+						FromDate       krakenDate `json:"fromDate"`
+						ToDate         krakenDate `json:"toDate"`
+						OpeningBalance int        `json:"openingBalance"`
+						ClosingBalance int        `json:"closingBalance"`
+						TotalCharges   BillTotal  `json:"totalCharges"`
+						TotalCredits   BillTotal  `json:"totalCredits"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"bills"`
+		} `json:"account"`
+	}
+
+	vars := connectionVariables(options.AccountNumber, options.PageSize, options.Page)
+	if err := s.client.GraphQL.QueryWithContext(ctx, accountBillsQuery, vars, &res); err != nil {
+		return nil, err
+	}
+
+	bills := res.Account.Bills
+	out := AccountBillsListOutput{}
+	out.Count, out.Next, out.Previous = bills.page()
+	for _, e := range bills.Edges {
+		n := e.Node
+		out.Results = append(out.Results, Bill{
+			ID:             n.ID,
+			BillType:       n.BillType,
+			IssuedDate:     time.Time(n.IssuedDate),
+			FromDate:       time.Time(n.FromDate),
+			ToDate:         time.Time(n.ToDate),
+			OpeningBalance: n.OpeningBalance,
+			ClosingBalance: n.ClosingBalance,
+			TotalCharges:   n.TotalCharges,
+			TotalCredits:   n.TotalCredits,
+		})
+	}
+
+	return &out, nil
+}
+
+// ListBillsPages same as ListBills except it returns all pages in one request.
+func (s *AccountService) ListBillsPages(options *AccountBillsListOptions) (*AccountBillsListOutput, error) {
+	return s.ListBillsPagesWithContext(context.Background(), options)
+}
+
+// ListBillsPagesWithContext same as ListBillsPages except it takes a Context.
+func (s *AccountService) ListBillsPagesWithContext(ctx context.Context, options *AccountBillsListOptions) (*AccountBillsListOutput, error) {
+	ctx, span := s.client.startPagesSpan(ctx, Operation{Service: "Account", Method: "ListBillsPages", PathTemplate: "/" + graphQLPath})
+	defer span.End()
+
+	options.PageSize = Int(100)
+	options.Page = nil
+
+	fullResp := AccountBillsListOutput{}
+	var lastPage bool
+
+	for !lastPage {
+		page, err := s.ListBillsWithContext(ctx, options)
+		if err != nil {
+			return nil, spanError(span, err)
+		}
+		fullResp.Count = page.Count
+		fullResp.Results = append(fullResp.Results, page.Results...)
+		if page.Next == "" {
+			lastPage = true
+		}
+		options.Page = String(page.Next)
+	}
+
+	endPagesSpan(span, len(fullResp.Results))
+	return &fullResp, nil
+}
+
+// AccountTransactionsListOptions is the options for ListTransactions.
+type AccountTransactionsListOptions struct {
+	// The octopus account number to be retrieved.
+	AccountNumber string
+
+	// Page size of returned results. Default is 20.
+	PageSize *int
+
+	// Pagination cursor to be returned on this request, the Next value of a previous output.
+	Page *string
+}
+
+// AccountTransactionsListOutput is the returned struct from ListTransactions. Next and Previous are page
+// cursors rather than links, they are blank when there is no further page.
+type AccountTransactionsListOutput struct {
+	Count    int
+	Next     string
+	Previous string
+	Results  []Transaction
+}
+
+// TransactionType is the kind of ledger entry.
+type TransactionType string
+
+const (
+	TransactionTypeCharge  TransactionType = "Charge"
+	TransactionTypePayment TransactionType = "Payment"
+	TransactionTypeCredit  TransactionType = "Credit"
+	TransactionTypeRefund  TransactionType = "Refund"
+)
+
+// Transaction is a single entry in the account ledger. Amounts are in pence.
+type Transaction struct {
+	ID         string
+	Type       TransactionType
+	Title      string
+	PostedDate time.Time
+
+	Net   int
+	Tax   int
+	Gross int
+
+	// The account balance after this transaction.
+	BalanceCarriedForward int
+
+	// Held transactions are not yet reflected in the balance.
+	IsHeld bool
+}
+
+const accountTransactionsQuery = `query AccountTransactions($accountNumber: String!, $first: Int, $after: String) {
+  account(accountNumber: $accountNumber) {
+    transactions(first: $first, after: $after) {
+      totalCount
+      pageInfo {
+        hasNextPage
+        endCursor
+        hasPreviousPage
+        startCursor
+      }
+      edges {
+        node {
+          __typename
+          id
+          title
+          postedDate
+          isHeld
+          balanceCarriedForward
+          amounts {
+            net
+            tax
+            gross
+          }
+        }
+      }
+    }
+  }
+}`
+
+// ListTransactions return the ledger of charges, payments and credits for an account, most recent first.
+// This endpoint is paginated, it will return next and previous cursors if there is more data, you are
+// responsible to request the next page if required.
+func (s *AccountService) ListTransactions(options *AccountTransactionsListOptions) (*AccountTransactionsListOutput, error) {
+	return s.ListTransactionsWithContext(context.Background(), options)
+}
+
+// ListTransactionsWithContext same as ListTransactions except it takes a Context.
+func (s *AccountService) ListTransactionsWithContext(ctx context.Context, options *AccountTransactionsListOptions) (*AccountTransactionsListOutput, error) {
+	var res struct {
+		Account struct {
+			Transactions struct {
+				graphQLConnection
+				Edges []struct {
+					Node struct {
+						TypeName              string     `json:"__typename"`
+						ID                    string     `json:"id"`
+						Title                 string     `json:"title"`
+						PostedDate            krakenDate `json:"postedDate"`
+						IsHeld                bool       `json:"isHeld"`
+						BalanceCarriedForward int        `json:"balanceCarriedForward"`
+						Amounts               struct {
+							Net   int `json:"net"`
+							Tax   int `json:"tax"`
+							Gross int `json:"gross"`
+						} `json:"amounts"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"transactions"`
+		} `json:"account"`
+	}
+
+	vars := connectionVariables(options.AccountNumber, options.PageSize, options.Page)
+	if err := s.client.GraphQL.QueryWithContext(ctx, accountTransactionsQuery, vars, &res); err != nil {
+		return nil, err
+	}
+
+	transactions := res.Account.Transactions
+	out := AccountTransactionsListOutput{}
+	out.Count, out.Next, out.Previous = transactions.page()
+	for _, e := range transactions.Edges {
+		n := e.Node
+		out.Results = append(out.Results, Transaction{
+			ID:                    n.ID,
+			Type:                  TransactionType(n.TypeName),
+			Title:                 n.Title,
+			PostedDate:            time.Time(n.PostedDate),
+			Net:                   n.Amounts.Net,
+			Tax:                   n.Amounts.Tax,
+			Gross:                 n.Amounts.Gross,
+			BalanceCarriedForward: n.BalanceCarriedForward,
+			IsHeld:                n.IsHeld,
+		})
+	}
+
+	return &out, nil
+}
+
+// ListTransactionsPages same as ListTransactions except it returns all pages in one request.
+func (s *AccountService) ListTransactionsPages(options *AccountTransactionsListOptions) (*AccountTransactionsListOutput, error) {
+	return s.ListTransactionsPagesWithContext(context.Background(), options)
+}
+
+// ListTransactionsPagesWithContext same as ListTransactionsPages except it takes a Context.
+func (s *AccountService) ListTransactionsPagesWithContext(ctx context.Context, options *AccountTransactionsListOptions) (*AccountTransactionsListOutput, error) {
+	ctx, span := s.client.startPagesSpan(ctx, Operation{Service: "Account", Method: "ListTransactionsPages", PathTemplate: "/" + graphQLPath})
+	defer span.End()
+
+	options.PageSize = Int(100)
+	options.Page = nil
+
+	fullResp := AccountTransactionsListOutput{}
+	var lastPage bool
+
+	for !lastPage {
+		page, err := s.ListTransactionsWithContext(ctx, options)
+		if err != nil {
+			return nil, spanError(span, err)
+		}
+		fullResp.Count = page.Count
+		fullResp.Results = append(fullResp.Results, page.Results...)
+		if page.Next == "" {
+			lastPage = true
+		}
+		options.Page = String(page.Next)
+	}
+
+	endPagesSpan(span, len(fullResp.Results))
+	return &fullResp, nil
+}
+
+// graphQLConnection is the paging information of a Relay style GraphQL connection.
+type graphQLConnection struct {
+	TotalCount int `json:"totalCount"`
+	PageInfo   struct {
+		HasNextPage     bool   `json:"hasNextPage"`
+		EndCursor       string `json:"endCursor"`
+		HasPreviousPage bool   `json:"hasPreviousPage"`
+		StartCursor     string `json:"startCursor"`
+	} `json:"pageInfo"`
+}
+
+// page maps the connection onto the count, next and previous fields used by paginated outputs.
+func (c graphQLConnection) page() (count int, next, previous string) {
+	count = c.TotalCount
+	if c.PageInfo.HasNextPage {
+		next = c.PageInfo.EndCursor
+	}
+	if c.PageInfo.HasPreviousPage {
+		previous = c.PageInfo.StartCursor
+	}
+	return count, next, previous
+}
+
+func connectionVariables(accountNumber string, pageSize *int, page *string) map[string]interface{} {
+	vars := map[string]interface{}{"accountNumber": accountNumber}
+	if pageSize != nil {
+		vars["first"] = *pageSize
+	}
+	if page != nil && *page != "" {
+		vars["after"] = *page
+	}
+	return vars
+}
+
+// krakenDate decodes a GraphQL Date, "2006-01-02", as midnight UK time.
+type krakenDate time.Time
+
+func (d *krakenDate) UnmarshalJSON(b []byte) error {
+	var v string
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v == "" {
+		*d = krakenDate{}
+		return nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, UKLocation())
+	if err != nil {
+		return fmt.Errorf("invalid date %q: %w", v, err)
+	}
+	*d = krakenDate(t)
+	return nil
+}
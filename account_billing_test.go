@@ -0,0 +1,163 @@
+package octopusenergy_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+// connectionPage returns the paging information of page n of pages, the last page still having an
+// end cursor as the real API does.
+func connectionPage(n, pages, total int) string {
+	return fmt.Sprintf(`"totalCount":%d,"pageInfo":{"hasNextPage":%t,"endCursor":"cursor-%d","hasPreviousPage":%t,"startCursor":"start-%d"}`,
+		total, n < pages, n, n > 1, n)
+}
+
+// pageNumber returns which page a query asks for from its after cursor.
+func pageNumber(vars map[string]interface{}) int {
+	var n int
+	if after, ok := vars["after"].(string); ok {
+		fmt.Sscanf(after, "cursor-%d", &n)
+	}
+	return n + 1
+}
+
+func TestAccountGetBalance(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	balances := map[string]int{"A-AAAA1111": 12345, "A-BBBB2222": -6789}
+	srv.GraphQL = krakenGraphQL(func(query string, vars map[string]interface{}) string {
+		return fmt.Sprintf(`{"data":{"account":{"balance":%d}}}`, balances[vars["accountNumber"].(string)])
+	})
+
+	for number, pence := range balances {
+		res, err := srv.Client().Account.GetBalance(&octopusenergy.AccountBalanceGetOptions{AccountNumber: number})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// in credit is positive, in debt negative, both in pence
+		if res.Balance != pence {
+			t.Errorf("%s: expected a balance of %dp, got %d", number, pence, res.Balance)
+		}
+	}
+}
+
+func TestAccountListBillsPages(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	var requested []int
+	srv.GraphQL = krakenGraphQL(func(query string, vars map[string]interface{}) string {
+		n := pageNumber(vars)
+		requested = append(requested, n)
+		if vars["first"] != float64(100) {
+			return `{"errors":[{"message":"expected pages of 100"}]}`
+		}
+		return fmt.Sprintf(`{"data":{"account":{"bills":{%s,"edges":[
+			{"node":{"id":"%d","billType":"STATEMENT","issuedDate":"2021-0%d-02","fromDate":"2021-0%d-01","toDate":"2021-0%d-30",
+				"openingBalance":-1000,"closingBalance":500,"totalCharges":{"netTotal":5000,"taxTotal":250,"grossTotal":5250},"totalCredits":{"netTotal":0,"taxTotal":0,"grossTotal":0}}},
+			{"node":{"id":"%d-interim","billType":"INTERIM","issuedDate":"2021-0%d-15","fromDate":"","toDate":""}}
+		]}}}}`, connectionPage(n, 3, 6), n, n+5, n+4, n+4, n, n+4)
+	})
+
+	res, err := srv.Client().Account.ListBillsPages(&octopusenergy.AccountBillsListOptions{AccountNumber: "A-AAAA1111"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(requested) != "[1 2 3]" {
+		t.Errorf("expected 3 pages walked in order, got %v", requested)
+	}
+	if res.Count != 6 || len(res.Results) != 6 {
+		t.Fatalf("expected 6 bills, got %d of %d", len(res.Results), res.Count)
+	}
+
+	bill := res.Results[0]
+	// dates are midnight in the UK, an hour before midnight UTC in summer
+	if !bill.FromDate.Equal(time.Date(2021, 4, 30, 23, 0, 0, 0, time.UTC)) || !bill.IssuedDate.Equal(time.Date(2021, 6, 1, 23, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected dates %s to %s issued %s", bill.FromDate, bill.ToDate, bill.IssuedDate)
+	}
+	if bill.OpeningBalance != -1000 || bill.ClosingBalance != 500 || bill.TotalCharges.GrossTotal != 5250 || bill.TotalCharges.TaxTotal != 250 {
+		t.Errorf("unexpected statement %+v", bill)
+	}
+	if interim := res.Results[1]; interim.BillType != "INTERIM" || !interim.FromDate.IsZero() || interim.TotalCharges.GrossTotal != 0 {
+		t.Errorf("unexpected interim bill %+v", interim)
+	}
+
+	// a single page keeps its cursors, blank on the last page
+	page, err := srv.Client().Account.ListBills(&octopusenergy.AccountBillsListOptions{AccountNumber: "A-AAAA1111", PageSize: octopusenergy.Int(100), Page: octopusenergy.String("cursor-2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Next != "" || page.Previous != "start-3" {
+		t.Errorf("unexpected cursors next %q previous %q", page.Next, page.Previous)
+	}
+}
+
+func TestAccountListTransactionsPages(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	srv.GraphQL = krakenGraphQL(func(query string, vars map[string]interface{}) string {
+		if pageNumber(vars) == 1 {
+			return `{"data":{"account":{"transactions":{` + connectionPage(1, 2, 3) + `,"edges":[
+				{"node":{"__typename":"Charge","id":"1","title":"Electricity","postedDate":"2021-01-31","isHeld":false,"balanceCarriedForward":-4200,"amounts":{"net":4000,"tax":200,"gross":4200}}},
+				{"node":{"__typename":"Payment","id":"2","title":"Direct debit","postedDate":"2021-01-15","isHeld":true,"balanceCarriedForward":0,"amounts":{"net":5000,"tax":0,"gross":5000}}}
+			]}}}}`
+		}
+		return `{"data":{"account":{"transactions":{` + connectionPage(2, 2, 3) + `,"edges":[
+			{"node":{"__typename":"Credit","id":"3","title":"Referral","postedDate":"2021-01-01","isHeld":false,"balanceCarriedForward":5000,"amounts":{"net":5000,"tax":0,"gross":5000}}}
+		]}}}}`
+	})
+
+	res, err := srv.Client().Account.ListTransactionsPages(&octopusenergy.AccountTransactionsListOptions{AccountNumber: "A-AAAA1111"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Count != 3 || len(res.Results) != 3 {
+		t.Fatalf("expected 3 transactions, got %d of %d", len(res.Results), res.Count)
+	}
+	charge, payment, credit := res.Results[0], res.Results[1], res.Results[2]
+	if charge.Type != octopusenergy.TransactionTypeCharge || charge.Gross != 4200 || charge.Tax != 200 || charge.BalanceCarriedForward != -4200 {
+		t.Errorf("unexpected charge %+v", charge)
+	}
+	if payment.Type != octopusenergy.TransactionTypePayment || !payment.IsHeld {
+		t.Errorf("unexpected payment %+v", payment)
+	}
+	if credit.Type != octopusenergy.TransactionTypeCredit || !credit.PostedDate.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected credit %+v", credit)
+	}
+}
+
+func TestAccountBillingErrors(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	srv.GraphQL = krakenGraphQL(func(query string, vars map[string]interface{}) string {
+		switch {
+		case strings.Contains(query, "transactions"):
+			return `{"data":{"account":{"transactions":{"totalCount":1,"edges":[{"node":{"__typename":"Charge","postedDate":"31/01/2021"}}]}}}}`
+		case pageNumber(vars) == 2:
+			return `{"errors":[{"message":"Unauthorized.","extensions":{"errorCode":"KT-CT-4301"}}]}`
+		default:
+			return `{"data":{"account":{"bills":{` + connectionPage(1, 2, 2) + `,"edges":[{"node":{"id":"1","issuedDate":"2021-01-02"}}]}}}}`
+		}
+	})
+	client := srv.Client()
+
+	// an error part way through the pages is returned rather than a partial result
+	res, err := client.Account.ListBillsPages(&octopusenergy.AccountBillsListOptions{AccountNumber: "A-AAAA1111"})
+	var gqlErrs octopusenergy.GraphQLErrors
+	if res != nil || !errors.As(err, &gqlErrs) || !gqlErrs.HasCode("KT-CT-4301") {
+		t.Errorf("expected error KT-CT-4301, got %v and %v", res, err)
+	}
+
+	if _, err := client.Account.ListTransactions(&octopusenergy.AccountTransactionsListOptions{AccountNumber: "A-AAAA1111"}); err == nil || !strings.Contains(err.Error(), `invalid date "31/01/2021"`) {
+		t.Errorf("expected an invalid date error, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package octopusenergy
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AgreementTimeline is the agreements of a meter point ordered by ValidFrom, answering which tariff
+// applied at any moment.
+type AgreementTimeline []Agreement
+
+// NewAgreementTimeline returns the agreements as a timeline. Agreements can be in any order.
+func NewAgreementTimeline(agreements []Agreement) AgreementTimeline {
+	return AgreementTimeline(sortedAgreements(agreements))
+}
+
+// At returns the agreement in force at t, false if there was none.
+func (t AgreementTimeline) At(at time.Time) (Agreement, bool) {
+	for i := len(t) - 1; i >= 0; i-- {
+		a := t[i]
+		if a.ValidFrom.After(at) {
+			continue
+		}
+		if a.ValidTo == nil || a.ValidTo.After(at) {
+			return a, true
+		}
+		return Agreement{}, false
+	}
+	return Agreement{}, false
+}
+
+// TariffSegment is a period of time during which a single tariff applied.
+type TariffSegment struct {
+	TariffCode string
+	From       time.Time
+	To         time.Time
+}
+
+// Segments splits the period from (inclusive) to (exclusive) into the tariffs that applied, in order.
+// Times not covered by any agreement are left out.
+func (t AgreementTimeline) Segments(from, to time.Time) []TariffSegment {
+	var segments []TariffSegment
+	for _, a := range t {
+		start, end := a.ValidFrom, to
+		if a.ValidTo != nil && a.ValidTo.Before(end) {
+			end = *a.ValidTo
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if !start.Before(end) {
+			continue
+		}
+
+		// join consecutive agreements on the same tariff, for example after a renewal
+		if n := len(segments); n > 0 && segments[n-1].TariffCode == a.TariffCode && segments[n-1].To.Equal(start) {
+			segments[n-1].To = end
+			continue
+		}
+		segments = append(segments, TariffSegment{TariffCode: a.TariffCode, From: start, To: end})
+	}
+	return segments
+}
+
+// Timeline returns the meter point's agreements as a timeline.
+func (mp HouseholdMeterPoint) Timeline() AgreementTimeline {
+	return NewAgreementTimeline(mp.Agreements)
+}
+
+// TariffChargesTimelineGetOptions is the options for GetTimeline.
+type TariffChargesTimelineGetOptions struct {
+	// The agreements of the meter point.
+	Agreements AgreementTimeline
+
+	// Fueltype: electricity or gas
+	FuelType FuelType
+
+	// The type of charge
+	Rate Rate
+
+	// The period to fetch charges for, from (inclusive) to (exclusive).
+	PeriodFrom time.Time
+	PeriodTo   time.Time
+}
+
+// TariffChargesTimelineGetOutput is the returned struct from GetTimeline.
+type TariffChargesTimelineGetOutput struct {
+	// The charges of each tariff, clipped to the segment of the period it applied to.
+	Segments []TariffSegmentCharges
+
+	// Every segment's charges in one list ordered by ValidFrom, ready for CostConsumption.
+	Results []TariffCharge
+}
+
+// TariffSegmentCharges is the charges of the tariff of a segment.
+type TariffSegmentCharges struct {
+	TariffSegment
+	Charges []TariffCharge
+}
+
+// GetTimeline fetches the charges of whichever tariff applied at each point in a period, so charges
+// are right either side of a tariff switch. Charges are clipped to their segment.
+func (s *TariffChargeService) GetTimeline(options *TariffChargesTimelineGetOptions) (*TariffChargesTimelineGetOutput, error) {
+	return s.GetTimelineWithContext(context.Background(), options)
+}
+
+// GetTimelineWithContext same as GetTimeline except it takes a Context.
+func (s *TariffChargeService) GetTimelineWithContext(ctx context.Context, options *TariffChargesTimelineGetOptions) (*TariffChargesTimelineGetOutput, error) {
+	if !options.PeriodFrom.Before(options.PeriodTo) {
+		return nil, errors.New("period from must be before period to")
+	}
+
+	out := TariffChargesTimelineGetOutput{}
+	for _, segment := range options.Agreements.Segments(options.PeriodFrom, options.PeriodTo) {
+		res, err := s.GetPagesWithContext(ctx, &TariffChargesGetOptions{
+			ProductCode: TariffProductCode(segment.TariffCode),
+			TariffCode:  segment.TariffCode,
+			FuelType:    options.FuelType,
+			Rate:        options.Rate,
+			PeriodFrom:  Time(segment.From),
+			PeriodTo:    Time(segment.To),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		charges := clipCharges(res.Results, segment.From, segment.To)
+		out.Segments = append(out.Segments, TariffSegmentCharges{TariffSegment: segment, Charges: charges})
+		out.Results = append(out.Results, charges...)
+	}
+
+	return &out, nil
+}
+
+// clipCharges returns the charges overlapping from to to, ordered by ValidFrom, with their validity
+// limited to that period.
+func clipCharges(charges []TariffCharge, from, to time.Time) []TariffCharge {
+	var out []TariffCharge
+	for _, c := range NewChargeIndex(charges) {
+		if c.ValidTo.IsZero() || c.ValidTo.After(to) {
+			c.ValidTo = to
+		}
+		if c.ValidFrom.Before(from) {
+			c.ValidFrom = from
+		}
+		if c.ValidFrom.Before(c.ValidTo) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
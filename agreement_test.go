@@ -0,0 +1,99 @@
+package octopusenergy_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestAgreementTimeline(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	switched := time.Date(2021, 3, 15, 14, 30, 0, 0, time.UTC)
+	renewed := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	left := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	timeline := octopusenergy.NewAgreementTimeline([]octopusenergy.Agreement{
+		{TariffCode: "E-1R-AGILE-18-02-21-C", ValidFrom: renewed, ValidTo: &left},
+		{TariffCode: "E-1R-VAR-19-04-12-C", ValidFrom: start, ValidTo: &switched},
+		{TariffCode: "E-1R-AGILE-18-02-21-C", ValidFrom: switched, ValidTo: &renewed},
+	})
+
+	for at, want := range map[time.Time]string{
+		start.Add(-time.Minute):    "",
+		switched.Add(-time.Minute): "E-1R-VAR-19-04-12-C",
+		switched:                   "E-1R-AGILE-18-02-21-C",
+		renewed:                    "E-1R-AGILE-18-02-21-C",
+		left:                       "",
+	} {
+		a, ok := timeline.At(at)
+		if a.TariffCode != want || ok != (want != "") {
+			t.Errorf("at %s: expected %q, got %q", at, want, a.TariffCode)
+		}
+	}
+
+	segments := timeline.Segments(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC))
+	if len(segments) != 2 {
+		t.Fatalf("expected 2 segments, got %+v", segments)
+	}
+	if segments[0].TariffCode != "E-1R-VAR-19-04-12-C" || !segments[0].To.Equal(switched) {
+		t.Errorf("unexpected first segment %+v", segments[0])
+	}
+	if !segments[1].From.Equal(switched) || !segments[1].To.Equal(left) {
+		t.Errorf("expected the renewal to join the second segment, got %+v", segments[1])
+	}
+}
+
+func TestTariffChargesGetTimeline(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	switched := time.Date(2021, 3, 15, 14, 30, 0, 0, time.UTC)
+	to := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	srv.AddTariffCharges("VAR-19-04-12", octopusenergy.FuelTypeElectricity, "E-1R-VAR-19-04-12-C", octopusenergy.RateStandardUnit,
+		octopusenergy.TariffCharge{ValueExcVat: 20, ValueIncVat: 21, ValidFrom: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	)
+	var agile []octopusenergy.TariffCharge
+	for at := switched; at.Before(to); at = at.Add(30 * time.Minute) {
+		agile = append(agile, octopusenergy.TariffCharge{ValueExcVat: 10, ValueIncVat: 10.5, ValidFrom: at, ValidTo: at.Add(30 * time.Minute)})
+	}
+	srv.AddTariffCharges("AGILE-18-02-21", octopusenergy.FuelTypeElectricity, "E-1R-AGILE-18-02-21-C", octopusenergy.RateStandardUnit, agile...)
+
+	res, err := srv.Client().TariffCharge.GetTimeline(&octopusenergy.TariffChargesTimelineGetOptions{
+		Agreements: octopusenergy.NewAgreementTimeline([]octopusenergy.Agreement{
+			{TariffCode: "E-1R-VAR-19-04-12-C", ValidFrom: from.AddDate(-1, 0, 0), ValidTo: &switched},
+			{TariffCode: "E-1R-AGILE-18-02-21-C", ValidFrom: switched},
+		}),
+		FuelType:   octopusenergy.FuelTypeElectricity,
+		Rate:       octopusenergy.RateStandardUnit,
+		PeriodFrom: from,
+		PeriodTo:   to,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Segments) != 2 || len(res.Segments[0].Charges) != 1 || !res.Segments[0].Charges[0].ValidTo.Equal(switched) {
+		t.Fatalf("expected the variable rate clipped at the switch, got %+v", res.Segments)
+	}
+
+	// one kWh every half hour of the month
+	var intervals []octopusenergy.ConsumptionInterval
+	for at := from; at.Before(to); at = at.Add(30 * time.Minute) {
+		intervals = append(intervals, octopusenergy.ConsumptionInterval{Start: at, End: at.Add(30 * time.Minute), Consumption: 1})
+	}
+	cost := octopusenergy.CostConsumption(intervals, res.Results)
+	if len(cost.Unpriced) != 0 {
+		t.Fatalf("expected every interval priced, %d were not", len(cost.Unpriced))
+	}
+
+	before := switched.Sub(from).Hours() * 2
+	after := to.Sub(switched).Hours() * 2
+	if want := before*21 + after*10.5; math.Abs(cost.CostIncVat-want) > 1e-6 {
+		t.Errorf("expected cost %.2f, got %.2f", want, cost.CostIncVat)
+	}
+}
@@ -0,0 +1,107 @@
+//go:generate stringer -linecomment -type=Condition
+
+// Package alert watches published Octopus Energy unit rates and notifies when
+// prices cross user defined thresholds.
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Condition is the type of price check a Rule performs.
+type Condition int
+
+const (
+	ConditionBelow    Condition = iota // below
+	ConditionAbove                     // above
+	ConditionNegative                  // negative
+)
+
+// Rule describes when an alert should fire. Threshold is in pence per kWh including VAT
+// and is ignored for ConditionNegative.
+type Rule struct {
+	Condition Condition
+	Threshold float64
+}
+
+// Below returns a Rule that fires when the unit rate is strictly below threshold.
+func Below(threshold float64) Rule {
+	return Rule{Condition: ConditionBelow, Threshold: threshold}
+}
+
+// Above returns a Rule that fires when the unit rate is strictly above threshold.
+func Above(threshold float64) Rule {
+	return Rule{Condition: ConditionAbove, Threshold: threshold}
+}
+
+// Negative returns a Rule that fires when the unit rate is below zero, i.e. you are paid to use energy.
+func Negative() Rule {
+	return Rule{Condition: ConditionNegative}
+}
+
+// Matches reports whether the given unit rate (inc VAT) triggers the rule.
+func (r Rule) Matches(valueIncVat float64) bool {
+	switch r.Condition {
+	case ConditionBelow:
+		return valueIncVat < r.Threshold
+	case ConditionAbove:
+		return valueIncVat > r.Threshold
+	case ConditionNegative:
+		return valueIncVat < 0
+	}
+	return false
+}
+
+// String returns a short human readable description of the rule, it is also used as part
+// of the de-duplication key so should be stable.
+func (r Rule) String() string {
+	if r.Condition == ConditionNegative {
+		return r.Condition.String()
+	}
+	// every digit of the threshold is kept so rules with close thresholds have different keys
+	return fmt.Sprintf("%s %sp", r.Condition.String(), strconv.FormatFloat(r.Threshold, 'g', -1, 64))
+}
+
+// Alert is a single price slot that matched a Rule.
+type Alert struct {
+	// The rule which fired.
+	Rule Rule `json:"-"`
+
+	// Description of the rule which fired, example "below 5p".
+	Condition string `json:"condition"`
+
+	// The tariff code the rate belongs to.
+	TariffCode string `json:"tariff_code"`
+
+	// The unit rate in pence per kWh.
+	ValueExcVat float64 `json:"value_exc_vat"`
+	ValueIncVat float64 `json:"value_inc_vat"`
+
+	// The period the rate applies to.
+	ValidFrom time.Time `json:"valid_from"`
+	ValidTo   time.Time `json:"valid_to"`
+}
+
+// Key returns the identifier used to de-duplicate alerts, the same slot, tariff and rule
+// will always produce the same key.
+func (a Alert) Key() string {
+	return fmt.Sprintf("%s|%s|%s", a.TariffCode, a.Rule.String(), a.ValidFrom.UTC().Format(time.RFC3339))
+}
+
+// Title returns a short summary of the alert suitable for a notification heading.
+func (a Alert) Title() string {
+	return fmt.Sprintf("Octopus price %s", a.Rule.String())
+}
+
+// Message returns a one line human readable description of the alert.
+func (a Alert) Message() string {
+	return fmt.Sprintf("%s: %.2fp/kWh from %s to %s (%s)",
+		a.TariffCode,
+		a.ValueIncVat,
+		a.ValidFrom.Local().Format("Mon 2 Jan 15:04"),
+		a.ValidTo.Local().Format("15:04"),
+		a.Rule.String(),
+	)
+}
@@ -0,0 +1,26 @@
+package alert
+
+import "testing"
+
+func TestRuleString(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		want string
+	}{
+		{rule: Below(5), want: "below 5p"},
+		{rule: Above(35.5), want: "above 35.5p"},
+		{rule: Below(5.001), want: "below 5.001p"},
+		{rule: Negative(), want: "negative"},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.String(); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+
+	// rules differing past the second decimal place are not de-duplicated together
+	a, b := Alert{Rule: Below(5.001)}, Alert{Rule: Below(5.004)}
+	if a.Key() == b.Key() {
+		t.Errorf("expected different keys, both were %q", a.Key())
+	}
+}
@@ -0,0 +1,207 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+// Notification is something a Notifier can deliver, such as an Alert or an octopusenergy.Anomaly.
+type Notification interface {
+	// Key identifies the notification, the same event always has the same key.
+	Key() string
+
+	// Title is a short summary suitable for a notification heading.
+	Title() string
+
+	// Message is a one line human readable description.
+	Message() string
+}
+
+// Notifier delivers a Notification to somewhere a human will see it.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifierFunc adapts an ordinary function to the Notifier interface.
+type NotifierFunc func(ctx context.Context, n Notification) error
+
+// Notify calls f(ctx, n).
+func (f NotifierFunc) Notify(ctx context.Context, n Notification) error {
+	return f(ctx, n)
+}
+
+// Named returns n with a name, which a Watcher uses to remember which notifiers an alert has been sent
+// to. Name NotifierFuncs and notifiers from other packages, or a Watcher can only tell them apart by
+// their position in its Notifiers.
+func Named(name string, n Notifier) Notifier {
+	return namedNotifier{Notifier: n, name: name}
+}
+
+type namedNotifier struct {
+	Notifier
+	name string
+}
+
+// WebhookNotifier posts the notification as a JSON document to a generic HTTP endpoint, an Alert is
+// posted as is and anything else with its key, title and message.
+type WebhookNotifier struct {
+	// The URL notifications will be posted to.
+	URL string
+
+	// Extra headers added to every request, example an Authorization header.
+	Header http.Header
+
+	// The HTTP client to use when sending requests. Defaults to `http.DefaultClient`.
+	HTTPClient *http.Client
+}
+
+// Notify sends the notification to the configured webhook.
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	var payload interface{} = notification
+	if _, ok := notification.(Alert); !ok {
+		payload = struct {
+			Key     string `json:"key"`
+			Title   string `json:"title"`
+			Message string `json:"message"`
+		}{notification.Key(), notification.Title(), notification.Message()}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return post(ctx, n.HTTPClient, n.URL, "application/json; charset=utf-8", n.Header, body)
+}
+
+// SlackNotifier posts the notification to a Slack compatible incoming webhook.
+type SlackNotifier struct {
+	// The incoming webhook URL.
+	WebhookURL string
+
+	// The HTTP client to use when sending requests. Defaults to `http.DefaultClient`.
+	HTTPClient *http.Client
+}
+
+// Notify sends the notification to the configured Slack webhook.
+func (n *SlackNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{
+		Text: fmt.Sprintf("*%s*\n%s", notification.Title(), notification.Message()),
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, n.HTTPClient, n.WebhookURL, "application/json; charset=utf-8", nil, body)
+}
+
+// NtfyNotifier publishes the notification to an ntfy style HTTP push topic.
+type NtfyNotifier struct {
+	// Server base URL. Defaults to https://ntfy.sh.
+	Server string
+
+	// The topic to publish to.
+	Topic string
+
+	// Optional access token, sent as a Bearer token.
+	Token string
+
+	// Optional message priority, 1 (min) to 5 (max).
+	Priority int
+
+	// The HTTP client to use when sending requests. Defaults to `http.DefaultClient`.
+	HTTPClient *http.Client
+}
+
+// Notify publishes the notification to the configured topic.
+func (n *NtfyNotifier) Notify(ctx context.Context, notification Notification) error {
+	server := n.Server
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+
+	header := http.Header{}
+	header.Set("Title", notification.Title())
+	header.Set("Tags", "zap")
+	if n.Priority > 0 {
+		header.Set("Priority", fmt.Sprint(n.Priority))
+	}
+	if n.Token != "" {
+		header.Set("Authorization", "Bearer "+n.Token)
+	}
+
+	u := strings.TrimRight(server, "/") + "/" + n.Topic
+	return post(ctx, n.HTTPClient, u, "text/plain; charset=utf-8", header, []byte(notification.Message()))
+}
+
+// SMTPNotifier sends the notification as a plain text email.
+type SMTPNotifier struct {
+	// Address of the SMTP server, example "smtp.example.com:587".
+	Addr string
+
+	// Optional authentication, example smtp.PlainAuth.
+	Auth smtp.Auth
+
+	// Sender address.
+	From string
+
+	// Recipient addresses.
+	To []string
+
+	// sendMail is swapped out in tests, defaults to smtp.SendMail.
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Notify emails the notification to the configured recipients.
+func (n *SMTPNotifier) Notify(ctx context.Context, notification Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	send := n.sendMail
+	if send == nil {
+		send = smtp.SendMail
+	}
+	return send(n.Addr, n.Auth, n.From, n.To, n.message(notification))
+}
+
+// message builds the email for the notification.
+func (n *SMTPNotifier) message(notification Notification) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", notification.Title())
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&msg, "\r\n%s\r\n", notification.Message())
+	return msg.Bytes()
+}
+
+func post(ctx context.Context, client *http.Client, url, contentType string, header http.Header, body []byte) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("notification rejected, status code: %d", res.StatusCode)
+	}
+	return nil
+}
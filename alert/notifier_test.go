@@ -0,0 +1,154 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+var testAlert = Alert{
+	Rule:        Below(5),
+	Condition:   "below 5p",
+	TariffCode:  "E-1R-AGILE-18-02-21-H",
+	ValueExcVat: 4,
+	ValueIncVat: 4.2,
+	ValidFrom:   time.Date(2021, 3, 1, 2, 30, 0, 0, time.UTC),
+	ValidTo:     time.Date(2021, 3, 1, 3, 0, 0, 0, time.UTC),
+}
+
+// capture returns a server recording the last request's headers and body.
+func capture(t *testing.T, status int) (*httptest.Server, *http.Header, *[]byte) {
+	header, body := &http.Header{}, &[]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected a POST, got %s", r.Method)
+		}
+		*header = r.Header
+		*body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, header, body
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	srv, header, body := capture(t, http.StatusNoContent)
+
+	n := &WebhookNotifier{URL: srv.URL, Header: http.Header{"Authorization": {"Bearer secret"}}}
+	if err := n.Notify(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Get("Authorization") != "Bearer secret" || !strings.HasPrefix(header.Get("Content-Type"), "application/json") {
+		t.Errorf("unexpected headers %v", header)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(*body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["condition"] != "below 5p" || payload["tariff_code"] != "E-1R-AGILE-18-02-21-H" || payload["value_inc_vat"] != 4.2 ||
+		payload["valid_from"] != "2021-03-01T02:30:00Z" || payload["valid_to"] != "2021-03-01T03:00:00Z" {
+		t.Errorf("unexpected payload %s", *body)
+	}
+	if _, ok := payload["Rule"]; ok {
+		t.Errorf("expected the rule to be left out, got %s", *body)
+	}
+
+	rejected, _, _ := capture(t, http.StatusForbidden)
+	if err := (&WebhookNotifier{URL: rejected.URL}).Notify(context.Background(), testAlert); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected a rejected notification, got %v", err)
+	}
+}
+
+func TestSlackNotifier(t *testing.T) {
+	srv, _, body := capture(t, http.StatusOK)
+
+	if err := (&SlackNotifier{WebhookURL: srv.URL}).Notify(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+
+	var payload struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(*body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if want := "*Octopus price below 5p*\n" + testAlert.Message(); payload.Text != want {
+		t.Errorf("expected text %q, got %q", want, payload.Text)
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	var addr, from string
+	var to []string
+	var msg []byte
+	n := &SMTPNotifier{
+		Addr: "smtp.example.com:587",
+		From: "alerts@example.com",
+		To:   []string{"a@example.com", "b@example.com"},
+		sendMail: func(a string, _ smtp.Auth, f string, t []string, m []byte) error {
+			addr, from, to, msg = a, f, t, m
+			return nil
+		},
+	}
+	if err := n.Notify(context.Background(), testAlert); err != nil {
+		t.Fatal(err)
+	}
+
+	if addr != "smtp.example.com:587" || from != "alerts@example.com" || len(to) != 2 {
+		t.Errorf("unexpected envelope %s %s %v", addr, from, to)
+	}
+	want := "From: alerts@example.com\r\n" +
+		"To: a@example.com, b@example.com\r\n" +
+		"Subject: Octopus price below 5p\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + testAlert.Message() + "\r\n"
+	if string(msg) != want || string(n.message(testAlert)) != want {
+		t.Errorf("expected message %q, got %q", want, msg)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := n.Notify(ctx, testAlert); err == nil {
+		t.Error("expected an error with a cancelled context")
+	}
+}
+
+func TestNotifyAnomaly(t *testing.T) {
+	anomaly := octopusenergy.Anomaly{
+		Kind:     octopusenergy.AnomalyOvernight,
+		Severity: octopusenergy.SeverityHigh,
+		From:     time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2021, 3, 1, 6, 0, 0, 0, time.UTC),
+		Expected: 1.2,
+		Actual:   4.8,
+	}
+
+	ntfy, header, body := capture(t, http.StatusOK)
+	if err := (&NtfyNotifier{Server: ntfy.URL, Topic: "usage"}).Notify(context.Background(), anomaly); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("Title") != anomaly.Title() || string(*body) != anomaly.Message() {
+		t.Errorf("unexpected ntfy notification %q: %q", header.Get("Title"), *body)
+	}
+
+	webhook, _, body := capture(t, http.StatusOK)
+	if err := (&WebhookNotifier{URL: webhook.URL}).Notify(context.Background(), anomaly); err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(*body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["key"] != anomaly.Key() || payload["title"] != anomaly.Title() || payload["message"] != anomaly.Message() {
+		t.Errorf("unexpected webhook payload %s", *body)
+	}
+}
@@ -0,0 +1,110 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// Store records which alerts have already been sent so they are not repeated.
+type Store interface {
+	// Seen reports whether the key has already been marked.
+	Seen(key string) (bool, error)
+
+	// Mark records the key until expires, after which it may be forgotten.
+	Mark(key string, expires time.Time) error
+}
+
+// MemoryStore is a Store that only lives as long as the process.
+type MemoryStore struct {
+	mu   sync.Mutex
+	keys map[string]time.Time
+}
+
+// NewMemoryStore returns an empty in memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: map[string]time.Time{}}
+}
+
+// Seen reports whether the key has already been marked.
+func (s *MemoryStore) Seen(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.keys[key]
+	return ok, nil
+}
+
+// Mark records the key until expires.
+func (s *MemoryStore) Mark(key string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruneExpired(s.keys, time.Now())
+	s.keys[key] = expires
+	return nil
+}
+
+// FileStore is a Store persisted as JSON to a file, so alerts are not repeated across restarts.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+	keys map[string]time.Time
+}
+
+// NewFileStore loads, or creates on first Mark, a store at path.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, keys: map[string]time.Time{}}
+
+	b, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &s.keys); err != nil {
+			return nil, err
+		}
+	}
+	pruneExpired(s.keys, time.Now())
+	return s, nil
+}
+
+// Seen reports whether the key has already been marked.
+func (s *FileStore) Seen(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.keys[key]
+	return ok, nil
+}
+
+// Mark records the key until expires and writes the store to disk.
+func (s *FileStore) Mark(key string, expires time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pruneExpired(s.keys, time.Now())
+	s.keys[key] = expires
+
+	b, err := json.Marshal(s.keys)
+	if err != nil {
+		return err
+	}
+
+	// write then rename so a crash never leaves a half written file behind
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func pruneExpired(keys map[string]time.Time, now time.Time) {
+	for k, exp := range keys {
+		if exp.Before(now) {
+			delete(keys, k)
+		}
+	}
+}
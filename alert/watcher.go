@@ -0,0 +1,194 @@
+package alert
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+const defaultInterval = 15 * time.Minute
+
+// Watcher polls tariff charges and sends an Alert to every Notifier for each
+// newly published slot that matches one of its Rules.
+type Watcher struct {
+	// Client used to fetch tariff charges.
+	Client *octopusenergy.Client
+
+	// Options selects the tariff to watch, example an Agile standard unit rate. If PeriodFrom
+	// is nil, each poll starts from the current half hour so only upcoming slots are checked.
+	Options octopusenergy.TariffChargesGetOptions
+
+	// Rules that will trigger an alert.
+	Rules []Rule
+
+	// Notifiers that every alert is sent to. Notifiers from this package are told apart by where
+	// they send to, use Named for any others so they can be reordered without resending alerts.
+	Notifiers []Notifier
+
+	// Store used to de-duplicate alerts. Defaults to an in memory store, use a FileStore
+	// to de-duplicate across restarts.
+	Store Store
+
+	// How often Run polls for new rates. Defaults to 15 minutes.
+	Interval time.Duration
+
+	// Called with any error from a poll in Run, which otherwise keeps going. Optional.
+	OnError func(err error)
+
+	now func() time.Time
+}
+
+// NewWatcher returns a Watcher for the given tariff with default settings.
+func NewWatcher(client *octopusenergy.Client, options octopusenergy.TariffChargesGetOptions) *Watcher {
+	return &Watcher{
+		Client:   client,
+		Options:  options,
+		Store:    NewMemoryStore(),
+		Interval: defaultInterval,
+	}
+}
+
+// Check fetches the currently published rates once, sends any new alerts and
+// returns the alerts that were sent.
+func (w *Watcher) Check(ctx context.Context) ([]Alert, error) {
+	if w.Store == nil {
+		w.Store = NewMemoryStore()
+	}
+
+	// GetPages mutates its options, so work on a copy
+	options := w.Options
+	if options.PeriodFrom == nil {
+		options.PeriodFrom = octopusenergy.Time(w.clock().UTC().Truncate(30 * time.Minute))
+	}
+
+	rates, err := w.Client.TariffCharge.GetPagesWithContext(ctx, &options)
+	if err != nil {
+		return nil, err
+	}
+
+	var sent []Alert
+	var errs []string
+
+	for _, rate := range rates.Results {
+		for _, rule := range w.Rules {
+			if !rule.Matches(rate.ValueIncVat) {
+				continue
+			}
+
+			a := Alert{
+				Rule:        rule,
+				Condition:   rule.String(),
+				TariffCode:  options.TariffCode,
+				ValueExcVat: rate.ValueExcVat,
+				ValueIncVat: rate.ValueIncVat,
+				ValidFrom:   rate.ValidFrom,
+				ValidTo:     rate.ValidTo,
+			}
+
+			seen, err := w.Store.Seen(a.Key())
+			if err != nil {
+				return sent, err
+			}
+			if seen {
+				continue
+			}
+
+			if err := w.notify(ctx, a); err != nil {
+				// not marked, so it will be retried on the next poll by the notifiers that failed
+				errs = append(errs, err.Error())
+				continue
+			}
+			sent = append(sent, a)
+		}
+	}
+
+	if len(errs) > 0 {
+		return sent, fmt.Errorf("failed to send alerts: %s", strings.Join(errs, "; "))
+	}
+	return sent, nil
+}
+
+// Run calls Check every Interval until the context is cancelled.
+func (w *Watcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Check(ctx); err != nil && w.OnError != nil && ctx.Err() == nil {
+			w.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// notify sends the alert to every notifier that has not already been sent it, marking each one
+// as it succeeds, and marks the alert once all have.
+func (w *Watcher) notify(ctx context.Context, a Alert) error {
+	expires := a.ValidTo.Add(24 * time.Hour)
+	var errs []string
+	for i, n := range w.Notifiers {
+		key := a.Key() + "|" + notifierID(i, n)
+		seen, err := w.Store.Seen(key)
+		if err != nil {
+			return err
+		}
+		if seen {
+			continue
+		}
+		if err := n.Notify(ctx, a); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if err := w.Store.Mark(key, expires); err != nil {
+			return err
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %s", a.Key(), strings.Join(errs, ", "))
+	}
+	return w.Store.Mark(a.Key(), expires)
+}
+
+// notifierID identifies a notifier in the store without depending on its position in Notifiers where
+// possible. It is hashed as webhook URLs often contain secrets.
+func notifierID(i int, n Notifier) string {
+	var id string
+	switch n := n.(type) {
+	case namedNotifier:
+		id = "named " + n.name
+	case *WebhookNotifier:
+		id = "webhook " + n.URL
+	case *SlackNotifier:
+		id = "slack " + n.WebhookURL
+	case *NtfyNotifier:
+		id = "ntfy " + n.Server + " " + n.Topic
+	case *SMTPNotifier:
+		id = "smtp " + n.Addr + " " + strings.Join(n.To, ",")
+	default:
+		id = fmt.Sprintf("%T %d", n, i)
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (w *Watcher) clock() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,211 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+// rates are published for the following day, the store forgets alerts once
+// their slot has passed so base the fixture on tomorrow.
+var base = time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+
+var ratesBody = fmt.Sprintf(`{
+  "count": 3,
+  "next": null,
+  "previous": null,
+  "results": [
+    {"value_exc_vat": -1.0, "value_inc_vat": -1.05, "valid_from": %q, "valid_to": %q},
+    {"value_exc_vat": 4.0, "value_inc_vat": 4.2, "valid_from": %q, "valid_to": %q},
+    {"value_exc_vat": 30.0, "value_inc_vat": 31.5, "valid_from": %q, "valid_to": %q}
+  ]
+}`,
+	slot(4), slot(5),
+	slot(5), slot(6),
+	slot(34), slot(35),
+)
+
+func slot(n int) string {
+	return base.Add(time.Duration(n) * 30 * time.Minute).Format(time.RFC3339)
+}
+
+func TestWatcherCheck(t *testing.T) {
+	var query string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		fmt.Fprint(w, ratesBody)
+	}))
+	defer api.Close()
+
+	var pushed []string
+	ntfy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		pushed = append(pushed, r.URL.Path+" "+r.Header.Get("Title")+" "+string(b))
+	}))
+	defer ntfy.Close()
+
+	client := octopusenergy.NewClient(octopusenergy.NewConfig().WithEndpoint(api.URL))
+	storePath := filepath.Join(t.TempDir(), "alerts.json")
+
+	newWatcher := func() *Watcher {
+		store, err := NewFileStore(storePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := NewWatcher(client, octopusenergy.TariffChargesGetOptions{
+			ProductCode: octopusenergy.ProductCodeAgile180221,
+			TariffCode:  "E-1R-AGILE-18-02-21-H",
+			FuelType:    octopusenergy.FuelTypeElectricity,
+			Rate:        octopusenergy.RateStandardUnit,
+		})
+		w.Rules = []Rule{Negative(), Below(5), Above(30)}
+		w.Notifiers = []Notifier{&NtfyNotifier{Server: ntfy.URL, Topic: "prices"}}
+		w.Store = store
+		w.now = func() time.Time { return base.Add(107 * time.Minute) }
+		return w
+	}
+
+	sent, err := newWatcher().Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the negative slot matches both the negative and the below rule
+	if len(sent) != 4 {
+		t.Fatalf("expected 4 alerts, got %d: %v", len(sent), sent)
+	}
+	if len(pushed) != 4 {
+		t.Fatalf("expected 4 notifications, got %d", len(pushed))
+	}
+	if want := "period_from=" + base.Format("2006-01-02") + "T01%3A30%3A00Z"; !strings.Contains(query, want) {
+		t.Errorf("expected query %q to contain %q", query, want)
+	}
+
+	// a new watcher sharing the same file must not send anything again
+	sent, err = newWatcher().Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 0 || len(pushed) != 4 {
+		t.Fatalf("expected alerts to be de-duplicated, got %d sent and %d pushed", len(sent), len(pushed))
+	}
+}
+
+func TestWatcherCheckNotifyFailure(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ratesBody)
+	}))
+	defer api.Close()
+
+	fail := true
+	w := NewWatcher(octopusenergy.NewClient(octopusenergy.NewConfig().WithEndpoint(api.URL)), octopusenergy.TariffChargesGetOptions{
+		PeriodFrom: octopusenergy.Time(base),
+	})
+	w.Rules = []Rule{Negative()}
+	w.Notifiers = []Notifier{NotifierFunc(func(ctx context.Context, n Notification) error {
+		if fail {
+			return fmt.Errorf("offline")
+		}
+		return nil
+	})}
+
+	if _, err := w.Check(context.Background()); err == nil {
+		t.Fatal("expected error from failing notifier")
+	}
+
+	// failed alerts are not marked as seen so are retried
+	fail = false
+	sent, err := w.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("expected retried alert, got %d", len(sent))
+	}
+}
+
+func TestWatcherCheckPartialFailure(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, ratesBody)
+	}))
+	defer api.Close()
+
+	var delivered, attempts, restarts int
+	fail := true
+	storePath := filepath.Join(t.TempDir(), "alerts.json")
+	newWatcher := func() *Watcher {
+		store, err := NewFileStore(storePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := NewWatcher(octopusenergy.NewClient(octopusenergy.NewConfig().WithEndpoint(api.URL)), octopusenergy.TariffChargesGetOptions{
+			PeriodFrom: octopusenergy.Time(base),
+		})
+		w.Rules = []Rule{Negative()}
+		w.Store = store
+		w.Notifiers = []Notifier{
+			Named("log", NotifierFunc(func(ctx context.Context, n Notification) error {
+				delivered++
+				return nil
+			})),
+			Named("pager", NotifierFunc(func(ctx context.Context, n Notification) error {
+				attempts++
+				if fail {
+					return fmt.Errorf("offline")
+				}
+				return nil
+			})),
+		}
+		// notifiers are remembered by name, not position
+		if restarts%2 == 1 {
+			w.Notifiers[0], w.Notifiers[1] = w.Notifiers[1], w.Notifiers[0]
+		}
+		restarts++
+		return w
+	}
+
+	if _, err := newWatcher().Check(context.Background()); err == nil {
+		t.Fatal("expected error from failing notifier")
+	}
+
+	// after a restart only the notifier that failed is sent the alert again
+	fail = false
+	sent, err := newWatcher().Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || delivered != 1 || attempts != 2 {
+		t.Fatalf("expected 1 alert delivered once and retried once, got %d sent, %d delivered, %d attempts", len(sent), delivered, attempts)
+	}
+
+	if sent, _ := newWatcher().Check(context.Background()); len(sent) != 0 || delivered != 1 || attempts != 2 {
+		t.Errorf("expected no further notifications, got %d sent, %d delivered, %d attempts", len(sent), delivered, attempts)
+	}
+}
+
+func TestNotifierID(t *testing.T) {
+	webhook := &WebhookNotifier{URL: "https://example.com/hook"}
+	if notifierID(0, webhook) != notifierID(3, &WebhookNotifier{URL: "https://example.com/hook"}) {
+		t.Error("expected notifiers sending to the same place to share an id wherever they are")
+	}
+	if notifierID(0, webhook) == notifierID(0, &WebhookNotifier{URL: "https://example.com/other"}) {
+		t.Error("expected notifiers sending to different places to have different ids")
+	}
+	if notifierID(0, &NtfyNotifier{Topic: "hook"}) == notifierID(0, &SlackNotifier{WebhookURL: "hook"}) {
+		t.Error("expected notifiers of different types to have different ids")
+	}
+	if strings.Contains(notifierID(0, webhook), "example.com") {
+		t.Error("expected the URL not to be stored")
+	}
+	if notifierID(0, Named("pager", webhook)) != notifierID(1, Named("pager", &SlackNotifier{})) {
+		t.Error("expected named notifiers to be identified by name")
+	}
+}
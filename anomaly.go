@@ -0,0 +1,412 @@
+//go:generate stringer -linecomment -type=AnomalyKind,Severity
+
+package octopusenergy
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// AnomalyKind is the type of unusual consumption an Anomaly describes.
+type AnomalyKind int
+
+const (
+	AnomalyHighUsage      AnomalyKind = iota // high usage
+	AnomalyOvernight                         // overnight usage
+	AnomalyContinuousLoad                    // continuous load
+	AnomalyBaseloadStep                      // baseload step
+	AnomalySummerGas                         // summer gas usage
+)
+
+// Severity is how far from normal an Anomaly is.
+type Severity int
+
+const (
+	SeverityLow    Severity = iota // low
+	SeverityMedium                 // medium
+	SeverityHigh                   // high
+)
+
+// Anomaly is a period of consumption unusual for the household.
+type Anomaly struct {
+	Kind     AnomalyKind
+	Severity Severity
+
+	// The period the anomaly covers.
+	From time.Time
+	To   time.Time
+
+	// Consumption over the period in kWh, expected from the baseline and actually used. For
+	// AnomalyBaseloadStep these are the always-on consumption per day before and after the step.
+	Expected float64
+	Actual   float64
+}
+
+// Key returns an identifier for the anomaly, the same kind and period always produce the same key,
+// so it can be used to stop the same anomaly being notified twice.
+func (a Anomaly) Key() string {
+	return fmt.Sprintf("%s|%s|%s", a.Kind, a.From.UTC().Format(time.RFC3339), a.To.UTC().Format(time.RFC3339))
+}
+
+// Title returns a short summary of the anomaly suitable for a notification heading.
+func (a Anomaly) Title() string {
+	return fmt.Sprintf("Unusual %s (%s)", a.Kind, a.Severity)
+}
+
+// Message returns a one line human readable description of the anomaly.
+func (a Anomaly) Message() string {
+	if a.Kind == AnomalyBaseloadStep {
+		return fmt.Sprintf("always-on usage changed from %.2f kWh to %.2f kWh a day from %s",
+			a.Expected, a.Actual, a.From.Local().Format("Mon 2 Jan"))
+	}
+	return fmt.Sprintf("%.2f kWh used from %s to %s, %.2f kWh expected",
+		a.Actual, a.From.Local().Format("Mon 2 Jan 15:04"), a.To.Local().Format("Mon 2 Jan 15:04"), a.Expected)
+}
+
+// Baseline is a household's typical consumption for each weekday and half hour of the day.
+type Baseline struct {
+	location *time.Location
+	slots    [7][48][]float64
+}
+
+// NewBaseline learns the typical consumption of each weekday and half hour from historic half-hourly
+// intervals. Days and times are in loc, UKLocation if nil.
+func NewBaseline(intervals []ConsumptionInterval, loc *time.Location) *Baseline {
+	if loc == nil {
+		loc = UKLocation()
+	}
+	b := Baseline{location: loc}
+	for _, in := range intervals {
+		s := b.slot(in.Start)
+		*s = append(*s, in.Consumption)
+	}
+	return &b
+}
+
+// Expected returns the typical consumption in the half hour starting at t and how much it usually
+// varies, false if the baseline has no data for that weekday and time. The median and the median
+// absolute deviation, scaled to a standard deviation, are used so past anomalies do not skew them.
+func (b *Baseline) Expected(t time.Time) (typical, stdDev float64, ok bool) {
+	values := *b.slot(t)
+	if len(values) == 0 {
+		return 0, 0, false
+	}
+	typical = median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - typical)
+	}
+	return typical, 1.4826 * median(deviations), true
+}
+
+func (b *Baseline) slot(t time.Time) *[]float64 {
+	local := t.In(b.location)
+	return &b.slots[local.Weekday()][local.Hour()*2+local.Minute()/30]
+}
+
+// AnomalyOptions is the options for DetectAnomalies.
+type AnomalyOptions struct {
+	// The baseline to compare against. Default is a baseline learned from the intervals checked.
+	Baseline *Baseline
+
+	// The location days and times are in. Default is UKLocation.
+	Location *time.Location
+
+	// Fueltype: electricity or gas. Summer usage is only checked for gas.
+	FuelType FuelType
+
+	// The number of standard deviations above the baseline a half hour is unusual at. Default is 3.
+	Threshold float64
+
+	// The kWh above the baseline a half hour must also be to be unusual, so tiny variations in a
+	// quiet half hour are ignored. Default is 0.2.
+	MinExcess float64
+
+	// The hours of the night, from (inclusive) to (exclusive). Default is 0 to 6.
+	NightFrom, NightTo int
+
+	// Unusual usage lasting at least this long is reported as a continuous load, such as a heater
+	// left on. Default is 4 hours.
+	MinContinuous time.Duration
+
+	// The number of days either side of a day compared to find a step change in baseload. Default is 7.
+	StepWindow int
+
+	// The ratio between baseload before and after a day that is a step change. Default is 1.5.
+	StepRatio float64
+
+	// Daily gas above this many kWh in June, July or August is reported, since it usually means the
+	// heating is on. Default is 10.
+	SummerGasLimit float64
+}
+
+// DetectAnomalies compares half-hourly consumption against a baseline by weekday and time of day and
+// returns unusual periods ordered by start: high usage, high overnight usage, continuous loads, step
+// changes in baseload and, for gas, usage during the summer.
+func DetectAnomalies(intervals []ConsumptionInterval, options AnomalyOptions) []Anomaly {
+	if options.Location == nil {
+		options.Location = UKLocation()
+	}
+	if options.Baseline == nil {
+		options.Baseline = NewBaseline(intervals, options.Location)
+	}
+	if options.Threshold <= 0 {
+		options.Threshold = 3
+	}
+	if options.MinExcess <= 0 {
+		options.MinExcess = 0.2
+	}
+	if options.NightFrom == 0 && options.NightTo == 0 {
+		options.NightTo = 6
+	}
+	if options.MinContinuous <= 0 {
+		options.MinContinuous = 4 * time.Hour
+	}
+	if options.StepWindow <= 0 {
+		options.StepWindow = 7
+	}
+	if options.StepRatio <= 1 {
+		options.StepRatio = 1.5
+	}
+	if options.SummerGasLimit <= 0 {
+		options.SummerGasLimit = 10
+	}
+
+	sorted := sortedIntervals(intervals)
+	anomalies := highUsage(sorted, options)
+	anomalies = append(anomalies, baseloadSteps(sorted, options)...)
+	if options.FuelType == FuelTypeGas {
+		anomalies = append(anomalies, summerGas(sorted, options)...)
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].From.Before(anomalies[j].From)
+	})
+	return anomalies
+}
+
+// highUsage groups consecutive half hours well above the baseline into anomalies.
+func highUsage(intervals []ConsumptionInterval, options AnomalyOptions) []Anomaly {
+	var anomalies []Anomaly
+	var run []ConsumptionInterval
+	var expected, maxZ float64
+	endRun := func() {
+		if len(run) == 0 {
+			return
+		}
+		a := Anomaly{
+			Kind:     AnomalyHighUsage,
+			Severity: severity(maxZ / options.Threshold),
+			From:     run[0].Start,
+			To:       run[len(run)-1].End,
+			Expected: expected,
+		}
+		night := true
+		for _, in := range run {
+			a.Actual += in.Consumption
+			night = night && inHours(in.Start.In(options.Location), options.NightFrom, options.NightTo)
+		}
+		switch {
+		case a.To.Sub(a.From) >= options.MinContinuous:
+			a.Kind = AnomalyContinuousLoad
+		case night:
+			a.Kind = AnomalyOvernight
+		}
+		anomalies = append(anomalies, a)
+		run, expected, maxZ = nil, 0, 0
+	}
+
+	for _, in := range intervals {
+		typical, stdDev, ok := options.Baseline.Expected(in.Start)
+		// a floor on the deviation stops perfectly regular half hours making any change unusual
+		z := (in.Consumption - typical) / math.Max(stdDev, 0.05)
+		if !ok || in.Consumption-typical < options.MinExcess || z < options.Threshold {
+			endRun()
+			continue
+		}
+		if n := len(run); n > 0 && !run[n-1].End.Equal(in.Start) {
+			endRun()
+		}
+		run = append(run, in)
+		expected += typical
+		maxZ = math.Max(maxZ, z)
+	}
+	endRun()
+	return anomalies
+}
+
+// baseloadSteps compares the always-on consumption of the days either side of each day and reports
+// the day of the largest change where it changes by more than StepRatio.
+func baseloadSteps(intervals []ConsumptionInterval, options AnomalyOptions) []Anomaly {
+	days := dailyBaseloads(intervals, options.Location, options.NightFrom, options.NightTo, defaultBaseloadPercentile)
+	w := options.StepWindow
+
+	var anomalies []Anomaly
+	var best *Anomaly
+	var bestRatio float64
+	for i := w; i+w <= len(days); i++ {
+		before := mean(baseloadValues(days[i-w : i]))
+		after := mean(baseloadValues(days[i : i+w]))
+		ratio := math.Max(after, before) / math.Max(math.Min(after, before), 0.001)
+		// ignore changes too small to matter in a quiet household, under 50W
+		if ratio < options.StepRatio || math.Abs(after-before) < 0.025 {
+			if best != nil {
+				anomalies = append(anomalies, *best)
+				best = nil
+			}
+			continue
+		}
+		if best == nil || ratio > bestRatio {
+			best = &Anomaly{
+				Kind:     AnomalyBaseloadStep,
+				Severity: severity(ratio / options.StepRatio),
+				From:     days[i].Day,
+				To:       days[i+w-1].Day.AddDate(0, 0, 1),
+				Expected: before * 48,
+				Actual:   after * 48,
+			}
+			bestRatio = ratio
+		}
+	}
+	if best != nil {
+		anomalies = append(anomalies, *best)
+	}
+	return anomalies
+}
+
+// summerGas reports runs of summer days using more than SummerGasLimit.
+func summerGas(intervals []ConsumptionInterval, options AnomalyOptions) []Anomaly {
+	var anomalies []Anomaly
+	for _, day := range dailyTotals(intervals, options.Location) {
+		if m := day.Day.Month(); m < time.June || m > time.August || day.Consumption <= options.SummerGasLimit {
+			continue
+		}
+		end := day.Day.AddDate(0, 0, 1)
+		if n := len(anomalies); n > 0 && anomalies[n-1].To.Equal(day.Day) {
+			a := &anomalies[n-1]
+			a.To = end
+			a.Expected += options.SummerGasLimit
+			a.Actual += day.Consumption
+			a.Severity = severity(a.Actual / a.Expected)
+			continue
+		}
+		anomalies = append(anomalies, Anomaly{
+			Kind:     AnomalySummerGas,
+			Severity: severity(day.Consumption / options.SummerGasLimit),
+			From:     day.Day,
+			To:       end,
+			Expected: options.SummerGasLimit,
+			Actual:   day.Consumption,
+		})
+	}
+	return anomalies
+}
+
+// severity grades how many times over its threshold an anomaly is.
+func severity(times float64) Severity {
+	switch {
+	case times >= 3:
+		return SeverityHigh
+	case times >= 2:
+		return SeverityMedium
+	}
+	return SeverityLow
+}
+
+// dayConsumption is a value for a single local day.
+type dayConsumption struct {
+	Day         time.Time
+	Consumption float64
+}
+
+// dailyTotals sums consumption for each local day, in order.
+func dailyTotals(intervals []ConsumptionInterval, loc *time.Location) []dayConsumption {
+	var days []dayConsumption
+	for _, in := range intervals {
+		day := startOfDay(in.Start, loc)
+		if n := len(days); n > 0 && days[n-1].Day.Equal(day) {
+			days[n-1].Consumption += in.Consumption
+			continue
+		}
+		days = append(days, dayConsumption{Day: day, Consumption: in.Consumption})
+	}
+	return days
+}
+
+// dailyBaseloads returns, for each local day in order, the given percentile of the half-hourly
+// consumption between the night hours, a measure of the household's always-on load.
+func dailyBaseloads(intervals []ConsumptionInterval, loc *time.Location, nightFrom, nightTo int, percentile float64) []dayConsumption {
+	var days []dayConsumption
+	var night []float64
+	endDay := func() {
+		if n := len(days); n > 0 && len(night) > 0 {
+			days[n-1].Consumption = percentileOf(night, percentile)
+		}
+		night = nil
+	}
+
+	for _, in := range intervals {
+		local := in.Start.In(loc)
+		if !inHours(local, nightFrom, nightTo) {
+			continue
+		}
+		day := startOfDay(in.Start, loc)
+		if n := len(days); n == 0 || !days[n-1].Day.Equal(day) {
+			endDay()
+			days = append(days, dayConsumption{Day: day})
+		}
+		night = append(night, in.Consumption)
+	}
+	endDay()
+	return days
+}
+
+func baseloadValues(days []dayConsumption) []float64 {
+	values := make([]float64, len(days))
+	for i, d := range days {
+		values[i] = d.Consumption
+	}
+	return values
+}
+
+// inHours reports whether t is between the hours from (inclusive) and to (exclusive), wrapping past
+// midnight when from is after to.
+func inHours(t time.Time, from, to int) bool {
+	h := t.Hour()
+	if from <= to {
+		return h >= from && h < to
+	}
+	return h >= from || h < to
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	return percentileOf(values, 50)
+}
+
+// percentileOf returns the pth percentile of values, interpolating between the closest ranks.
+func percentileOf(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(rank-float64(lo))
+}
@@ -0,0 +1,92 @@
+package octopusenergy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+// householdConsumption returns half-hourly consumption for a household with a 0.1 kWh always-on
+// load, more during the day and most in the evening. Hours are in UTC, the UK in winter.
+func householdConsumption(from time.Time, days int) []octopusenergy.ConsumptionInterval {
+	intervals := intervalsBetween(from, from.AddDate(0, 0, days), 0)
+	for i := range intervals {
+		in := &intervals[i]
+		day := int(in.Start.Sub(from).Hours()) / 24
+		switch h := in.Start.Hour(); {
+		case h < 7:
+			in.Consumption = 0.1
+		case h >= 17 && h < 21:
+			in.Consumption = 0.6
+		default:
+			in.Consumption = 0.3
+		}
+		in.Consumption += 0.01 * float64(day%3)
+	}
+	return intervals
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	from := time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC)
+	intervals := householdConsumption(from, 42)
+
+	overnight := from.AddDate(0, 0, 16).Add(time.Hour)
+	heater := from.AddDate(0, 0, 19).Add(10 * time.Hour)
+	step := from.AddDate(0, 0, 28)
+	for i := range intervals {
+		in := &intervals[i]
+		switch {
+		case !in.Start.Before(overnight) && in.Start.Before(overnight.Add(2*time.Hour)):
+			in.Consumption = 1.5
+		case !in.Start.Before(heater) && in.Start.Before(heater.Add(6*time.Hour)):
+			in.Consumption += 1
+		case !in.Start.Before(step) && in.Start.Hour() < 7:
+			in.Consumption += 0.1
+		}
+	}
+
+	anomalies := octopusenergy.DetectAnomalies(intervals, octopusenergy.AnomalyOptions{Location: time.UTC})
+	if len(anomalies) != 3 {
+		t.Fatalf("expected 3 anomalies, got %+v", anomalies)
+	}
+
+	if a := anomalies[0]; a.Kind != octopusenergy.AnomalyOvernight || !a.From.Equal(overnight) || a.Actual != 6 || a.Severity != octopusenergy.SeverityHigh {
+		t.Errorf("expected high overnight usage, got %+v", a)
+	}
+	if a := anomalies[1]; a.Kind != octopusenergy.AnomalyContinuousLoad || !a.From.Equal(heater) || !a.To.Equal(heater.Add(6*time.Hour)) || a.Actual-a.Expected < 11.9 {
+		t.Errorf("expected a continuous load, got %+v", a)
+	}
+	if a := anomalies[2]; a.Kind != octopusenergy.AnomalyBaseloadStep || !a.From.Equal(step) || a.Actual < 1.8*a.Expected {
+		t.Errorf("expected the baseload to double, got %+v", a)
+	}
+}
+
+func TestDetectAnomaliesSummerGas(t *testing.T) {
+	from := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	intervals := intervalsBetween(from, from.AddDate(0, 0, 14), 0.1)
+	for i := range intervals {
+		// the heating comes on for two days
+		if d := intervals[i].Start.Day(); d == 9 || d == 10 {
+			intervals[i].Consumption = 0.5
+		}
+	}
+
+	anomalies := octopusenergy.DetectAnomalies(intervals, octopusenergy.AnomalyOptions{
+		FuelType: octopusenergy.FuelTypeGas,
+		Location: time.UTC,
+	})
+
+	var summer []octopusenergy.Anomaly
+	for _, a := range anomalies {
+		if a.Kind == octopusenergy.AnomalySummerGas {
+			summer = append(summer, a)
+		}
+	}
+	if len(summer) != 1 || !summer[0].From.Equal(from.AddDate(0, 0, 8)) || summer[0].Actual != 48 || summer[0].Severity != octopusenergy.SeverityMedium {
+		t.Fatalf("expected two days of summer gas usage, got %+v", summer)
+	}
+	if summer[0].Title() != "Unusual summer gas usage (medium)" {
+		t.Errorf("unexpected title %q", summer[0].Title())
+	}
+}
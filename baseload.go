@@ -0,0 +1,107 @@
+package octopusenergy
+
+import "time"
+
+// defaultBaseloadPercentile is the percentile of overnight half hours taken as a day's baseload, low
+// enough to miss the fridge and boiler cycling on but not a single faulty reading.
+const defaultBaseloadPercentile = 10
+
+// BaseloadOptions is the options for EstimateBaseload.
+type BaseloadOptions struct {
+	// The location days and times are in. Default is UKLocation.
+	Location *time.Location
+
+	// The hours of the night baseload is measured over, from (inclusive) to (exclusive). Default is 0 to 6.
+	NightFrom, NightTo int
+
+	// The percentile of each night's half-hourly consumption taken as its baseload. Default is 10.
+	Percentile float64
+
+	// The unit rate baseload is costed at, for example from TariffChargeService.GetAt. Not costed if nil.
+	UnitRate *TariffCharge
+}
+
+// DailyBaseload is the always-on load measured over one night.
+type DailyBaseload struct {
+	Day time.Time
+
+	// The average power, in watts.
+	Watts float64
+}
+
+// BaseloadReport is an estimate of a household's always-on load: the consumption of everything left
+// running, such as fridges, routers and appliances on standby.
+type BaseloadReport struct {
+	// The baseload of each night, in order.
+	Days []DailyBaseload
+
+	// The median of the nightly baseloads, in watts.
+	Watts float64
+
+	// How baseload is changing, in watts per 30 days, from a least squares fit of the nightly baseloads.
+	TrendWatts float64
+
+	// The consumption of the baseload over a year, in kWh.
+	AnnualConsumption float64
+
+	// The cost of the baseload over a year at UnitRate, in pence. Zero if no unit rate was given.
+	AnnualCostExcVat float64
+	AnnualCostIncVat float64
+}
+
+// EstimateBaseload estimates a household's always-on load from half-hourly electricity consumption as
+// a low percentile of each night's consumption, with its trend and annual consumption and cost.
+func EstimateBaseload(intervals []ConsumptionInterval, options BaseloadOptions) BaseloadReport {
+	if options.Location == nil {
+		options.Location = UKLocation()
+	}
+	if options.NightFrom == 0 && options.NightTo == 0 {
+		options.NightTo = 6
+	}
+	if options.Percentile <= 0 {
+		options.Percentile = defaultBaseloadPercentile
+	}
+
+	var report BaseloadReport
+	days := dailyBaseloads(sortedIntervals(intervals), options.Location, options.NightFrom, options.NightTo, options.Percentile)
+	if len(days) == 0 {
+		return report
+	}
+
+	watts := make([]float64, len(days))
+	for i, d := range days {
+		// kWh in a half hour to average watts
+		watts[i] = d.Consumption * 2000
+		report.Days = append(report.Days, DailyBaseload{Day: d.Day, Watts: watts[i]})
+	}
+	report.Watts = median(watts)
+	report.TrendWatts = trendPer30Days(report.Days)
+	report.AnnualConsumption = report.Watts / 1000 * 24 * 365
+
+	if options.UnitRate != nil {
+		report.AnnualCostExcVat = report.AnnualConsumption * options.UnitRate.ValueExcVat
+		report.AnnualCostIncVat = report.AnnualConsumption * options.UnitRate.ValueIncVat
+	}
+	return report
+}
+
+// trendPer30Days returns the least squares slope of the daily baseloads in watts per 30 days.
+func trendPer30Days(days []DailyBaseload) float64 {
+	if len(days) < 2 {
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for _, d := range days {
+		x := d.Day.Sub(days[0].Day).Hours() / 24
+		sumX += x
+		sumY += d.Watts
+		sumXY += x * d.Watts
+		sumXX += x * x
+	}
+	n := float64(len(days))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator * 30
+}
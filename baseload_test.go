@@ -0,0 +1,40 @@
+package octopusenergy_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+func TestEstimateBaseload(t *testing.T) {
+	rate := &octopusenergy.TariffCharge{ValueExcVat: 19, ValueIncVat: 20}
+
+	from := time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC)
+	intervals := householdConsumption(from, 28)
+	// one night a tumble dryer is left running
+	for i := range intervals {
+		if intervals[i].Start.Before(from.Add(24 * time.Hour)) {
+			intervals[i].Consumption = 1
+		}
+	}
+
+	report := octopusenergy.EstimateBaseload(intervals, octopusenergy.BaseloadOptions{Location: time.UTC, UnitRate: rate})
+	if len(report.Days) != 28 {
+		t.Fatalf("expected 28 nights, got %d", len(report.Days))
+	}
+	if report.Watts != 220 {
+		t.Errorf("expected a baseload of 220W, got %.2f", report.Watts)
+	}
+	if math.Abs(report.AnnualConsumption-220*8.76) > 1e-9 {
+		t.Errorf("expected %.2f kWh a year, got %.2f", 220*8.76, report.AnnualConsumption)
+	}
+	if math.Abs(report.AnnualCostIncVat-220*8.76*20) > 1e-6 {
+		t.Errorf("expected a cost of %.2fp a year, got %.2f", 220*8.76*20, report.AnnualCostIncVat)
+	}
+	// the first night pulls the trend down
+	if report.TrendWatts >= 0 {
+		t.Errorf("expected a falling trend, got %.2f", report.TrendWatts)
+	}
+}
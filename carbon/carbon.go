@@ -0,0 +1,211 @@
+// Package carbon fetches regional carbon intensity forecasts in the format of National Grid ESO's
+// Carbon Intensity API and uses them to work out the emissions of consumption and the greenest
+// time to use electricity.
+package carbon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is National Grid ESO's Carbon Intensity API.
+const DefaultBaseURL = "https://api.carbonintensity.org.uk/"
+
+// timeLayout is the layout of times in request paths.
+const timeLayout = "2006-01-02T15:04Z"
+
+// maxRange is the longest period the API returns in one request.
+const maxRange = 14 * 24 * time.Hour
+
+// Client fetches carbon intensity forecasts. The zero value is ready to use.
+type Client struct {
+	// Base URL of the API, change it to test against a local server. Defaults to DefaultBaseURL.
+	BaseURL string
+
+	// The HTTP client to use when sending requests. Defaults to `http.DefaultClient`.
+	HTTPClient *http.Client
+}
+
+// Intensity is the carbon intensity of electricity over a half hour.
+type Intensity struct {
+	From time.Time
+	To   time.Time
+
+	// Forecast carbon intensity in gCO2/kWh.
+	Forecast float64
+
+	// Actual carbon intensity in gCO2/kWh, only given nationally for the past so usually zero.
+	Actual float64
+
+	// The band the forecast falls in, example "very low", "moderate" or "very high".
+	Index string
+}
+
+// Value returns the actual intensity if known, otherwise the forecast.
+func (i Intensity) Value() float64 {
+	if i.Actual > 0 {
+		return i.Actual
+	}
+	return i.Forecast
+}
+
+// ForecastOptions is the options for Forecast.
+type ForecastOptions struct {
+	// The GSP group of the region, example "_C" or "C" for London, as returned by
+	// GridSupplyPointService or found by HouseholdService.
+	GSP string
+
+	// The period to fetch, from (inclusive) to (exclusive). A zero To fetches the 48 hour forecast
+	// from From, a zero From starts from now.
+	From time.Time
+	To   time.Time
+}
+
+type regionalResponse struct {
+	Data struct {
+		RegionID  int                 `json:"regionid"`
+		ShortName string              `json:"shortname"`
+		Data      []regionalIntensity `json:"data"`
+	} `json:"data"`
+}
+
+type regionalIntensity struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Intensity struct {
+		Forecast float64 `json:"forecast"`
+		Actual   float64 `json:"actual"`
+		Index    string  `json:"index"`
+	} `json:"intensity"`
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Forecast fetches the carbon intensity of the region of a GSP group over a period, ordered by time.
+// Periods longer than the API allows are fetched in several requests.
+func (c *Client) Forecast(options ForecastOptions) ([]Intensity, error) {
+	return c.ForecastWithContext(context.Background(), options)
+}
+
+// ForecastWithContext same as Forecast except it takes a Context.
+func (c *Client) ForecastWithContext(ctx context.Context, options ForecastOptions) ([]Intensity, error) {
+	region, ok := RegionID(options.GSP)
+	if !ok {
+		return nil, fmt.Errorf("no carbon intensity region for GSP group %q", options.GSP)
+	}
+
+	from := options.From
+	if from.IsZero() {
+		from = time.Now()
+	}
+	from = from.UTC().Truncate(30 * time.Minute)
+
+	if options.To.IsZero() {
+		page, err := c.regional(ctx, fmt.Sprintf("regional/intensity/%s/fw48h/regionid/%d", from.Format(timeLayout), region))
+		if err != nil {
+			return nil, err
+		}
+		return within(page, from, from.Add(48*time.Hour)), nil
+	}
+
+	var intensities []Intensity
+	for start := from; start.Before(options.To); start = start.Add(maxRange) {
+		end := start.Add(maxRange)
+		if end.After(options.To) {
+			end = options.To.UTC()
+		}
+		page, err := c.regional(ctx, fmt.Sprintf("regional/intensity/%s/%s/regionid/%d", start.Format(timeLayout), end.Format(timeLayout), region))
+		if err != nil {
+			return nil, err
+		}
+		intensities = append(intensities, within(page, start, end)...)
+	}
+	return intensities, nil
+}
+
+// within returns the intensities starting from from (inclusive) to to (exclusive). The API includes
+// the half hour ending at the start of a period, which would otherwise overlap the previous request.
+func within(intensities []Intensity, from, to time.Time) []Intensity {
+	var out []Intensity
+	for _, i := range intensities {
+		if !i.From.Before(from) && i.From.Before(to) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (c *Client) regional(ctx context.Context, path string) ([]Intensity, error) {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	u, err := url.Parse(strings.TrimRight(base, "/") + "/" + path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
+		var e errorResponse
+		if json.Unmarshal(body, &e) == nil && e.Error.Message != "" {
+			return nil, fmt.Errorf("carbon intensity request failed, status code: %d: %s", res.StatusCode, e.Error.Message)
+		}
+		return nil, fmt.Errorf("carbon intensity request failed, status code: %d", res.StatusCode)
+	}
+
+	var out regionalResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+
+	intensities := make([]Intensity, 0, len(out.Data.Data))
+	for _, d := range out.Data.Data {
+		from, err := time.Parse(timeLayout, d.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intensity from: %w", err)
+		}
+		to, err := time.Parse(timeLayout, d.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intensity to: %w", err)
+		}
+		intensities = append(intensities, Intensity{
+			From:     from,
+			To:       to,
+			Forecast: d.Intensity.Forecast,
+			Actual:   d.Intensity.Actual,
+			Index:    d.Intensity.Index,
+		})
+	}
+	return intensities, nil
+}
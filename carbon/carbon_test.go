@@ -0,0 +1,122 @@
+package carbon
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+var start = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// intensityAt is the fixture's intensity, lowest in the early hours.
+func intensityAt(t time.Time) float64 {
+	return 100 + 10*math.Abs(float64(t.Hour()-3))
+}
+
+// newServer serves regional intensities for London like the Carbon Intensity API, including the
+// half hour before the period as the real API does.
+func newServer(t *testing.T, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.Path)
+
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) != 6 || parts[0] != "regional" || parts[4] != "regionid" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if parts[5] != "13" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":"400 Bad Request","message":"Please enter a valid region ID"}}`))
+			return
+		}
+		from, _ := time.Parse(timeLayout, parts[2])
+		to := from.Add(48 * time.Hour)
+		if parts[3] != "fw48h" {
+			to, _ = time.Parse(timeLayout, parts[3])
+		}
+
+		var res regionalResponse
+		res.Data.RegionID = 13
+		res.Data.ShortName = "London"
+		for at := from.Add(-30 * time.Minute); at.Before(to); at = at.Add(30 * time.Minute) {
+			i := regionalIntensity{From: at.Format(timeLayout), To: at.Add(30 * time.Minute).Format(timeLayout)}
+			i.Intensity.Forecast = intensityAt(at)
+			res.Data.Data = append(res.Data.Data, i)
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+}
+
+func TestForecast(t *testing.T) {
+	var requests []string
+	srv := newServer(t, &requests)
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL}
+	intensities, err := c.Forecast(ForecastOptions{GSP: "C", From: start, To: start.AddDate(0, 0, 20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 {
+		t.Errorf("expected the period split into 2 requests, got %v", requests)
+	}
+	if len(intensities) != 20*48 || !intensities[0].From.Equal(start) {
+		t.Fatalf("expected 20 days of half hours from the start, got %d from %s", len(intensities), intensities[0].From)
+	}
+	for i := 1; i < len(intensities); i++ {
+		if !intensities[i].From.Equal(intensities[i-1].To) {
+			t.Fatalf("intensities are not continuous at %s", intensities[i].From)
+		}
+	}
+
+	if _, err := c.Forecast(ForecastOptions{GSP: "_Z"}); err == nil {
+		t.Error("expected an error for an unknown GSP group")
+	}
+	if _, err := c.Forecast(ForecastOptions{GSP: "_A", From: start}); err == nil || !strings.Contains(err.Error(), "valid region ID") {
+		t.Errorf("expected the API's error message, got %v", err)
+	}
+}
+
+func TestEmissionsAndLowestWindow(t *testing.T) {
+	var requests []string
+	srv := newServer(t, &requests)
+	defer srv.Close()
+
+	intensities, err := (&Client{BaseURL: srv.URL}).Forecast(ForecastOptions{GSP: "_C", From: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intensities) != 96 {
+		t.Fatalf("expected a 48 hour forecast, got %d half hours", len(intensities))
+	}
+
+	intervals := []octopusenergy.ConsumptionInterval{
+		{Start: start.Add(3 * time.Hour), End: start.Add(210 * time.Minute), Consumption: 2},
+		{Start: start.Add(12 * time.Hour), End: start.Add(750 * time.Minute), Consumption: 1},
+		{Start: start.Add(72 * time.Hour), End: start.Add(4350 * time.Minute), Consumption: 1},
+	}
+	emissions := CalculateEmissions(intervals, intensities)
+	if emissions.Grams != 2*100+1*190 || emissions.Consumption != 3 {
+		t.Errorf("unexpected emissions %+v", emissions)
+	}
+	if len(emissions.Unmatched) != 1 {
+		t.Errorf("expected the interval past the forecast unmatched, got %+v", emissions.Unmatched)
+	}
+
+	window, ok := LowestWindow(intensities, WindowOptions{Duration: 2 * time.Hour, NotBefore: start.Add(6 * time.Hour)})
+	if !ok {
+		t.Fatal("expected a window")
+	}
+	if !window.From.Equal(start.Add(26*time.Hour)) || !window.To.Equal(start.Add(28*time.Hour)) || window.Average != 105 {
+		t.Errorf("expected the early hours of the next day, got %+v", window)
+	}
+
+	if _, ok := LowestWindow(intensities, WindowOptions{Duration: time.Hour, NotBefore: start, NotAfter: start.Add(30 * time.Minute)}); ok {
+		t.Error("expected no window shorter than the duration")
+	}
+}
@@ -0,0 +1,122 @@
+package carbon
+
+import (
+	"sort"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+// IntervalEmissions is the emissions of a single consumption interval.
+type IntervalEmissions struct {
+	octopusenergy.ConsumptionInterval
+
+	// The carbon intensity used, in gCO2/kWh.
+	Intensity float64
+
+	// The emissions of the interval, in grams of CO2.
+	Grams float64
+}
+
+// Emissions is the result of calculating the emissions of consumption.
+type Emissions struct {
+	// Total consumption with a known intensity, in kWh.
+	Consumption float64
+
+	// Total emissions, in grams of CO2.
+	Grams float64
+
+	// The emissions of each interval with a known intensity.
+	Intervals []IntervalEmissions
+
+	// Intervals that had no intensity covering their start time.
+	Unmatched []octopusenergy.ConsumptionInterval
+}
+
+// CalculateEmissions works out the emissions of each interval from the intensity of the half hour it
+// started in. Intensities can be in any order.
+func CalculateEmissions(intervals []octopusenergy.ConsumptionInterval, intensities []Intensity) Emissions {
+	sorted := sortedIntensities(intensities)
+
+	var out Emissions
+	for _, in := range intervals {
+		i := sort.Search(len(sorted), func(i int) bool { return sorted[i].To.After(in.Start) })
+		if i == len(sorted) || sorted[i].From.After(in.Start) {
+			out.Unmatched = append(out.Unmatched, in)
+			continue
+		}
+		e := IntervalEmissions{ConsumptionInterval: in, Intensity: sorted[i].Value()}
+		e.Grams = in.Consumption * e.Intensity
+		out.Consumption += in.Consumption
+		out.Grams += e.Grams
+		out.Intervals = append(out.Intervals, e)
+	}
+	return out
+}
+
+// WindowOptions is the options for LowestWindow.
+type WindowOptions struct {
+	// How long the window must be, example how long an appliance runs for.
+	Duration time.Duration
+
+	// The window must not start before this time. Optional.
+	NotBefore time.Time
+
+	// The window must end by this time. Optional.
+	NotAfter time.Time
+}
+
+// Window is a period of time and its average carbon intensity.
+type Window struct {
+	From time.Time
+	To   time.Time
+
+	// The average carbon intensity over the window, in gCO2/kWh.
+	Average float64
+}
+
+// LowestWindow finds the period of at least Duration, over consecutive intensities, with the lowest
+// average carbon intensity. It returns false if no run of intensities is long enough.
+func LowestWindow(intensities []Intensity, options WindowOptions) (Window, bool) {
+	var sorted []Intensity
+	for _, i := range sortedIntensities(intensities) {
+		if i.From.Before(options.NotBefore) || (!options.NotAfter.IsZero() && i.To.After(options.NotAfter)) {
+			continue
+		}
+		sorted = append(sorted, i)
+	}
+
+	var best Window
+	var found bool
+	for start := range sorted {
+		var total float64
+		for end := start; end < len(sorted); end++ {
+			if end > start && !sorted[end].From.Equal(sorted[end-1].To) {
+				break
+			}
+			total += sorted[end].Value() * sorted[end].To.Sub(sorted[end].From).Hours()
+
+			length := sorted[end].To.Sub(sorted[start].From)
+			if length < options.Duration {
+				continue
+			}
+			average := total / length.Hours()
+			if !found || average < best.Average {
+				best = Window{From: sorted[start].From, To: sorted[end].To, Average: average}
+				found = true
+			}
+			break
+		}
+	}
+	return best, found
+}
+
+// sortedIntensities returns a copy of intensities ordered by From.
+func sortedIntensities(intensities []Intensity) []Intensity {
+	sorted := make([]Intensity, len(intensities))
+	copy(sorted, intensities)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].From.Before(sorted[j].From)
+	})
+	return sorted
+}
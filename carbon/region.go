@@ -0,0 +1,32 @@
+package carbon
+
+import "strings"
+
+// regions maps GSP groups to the region IDs of the Carbon Intensity API.
+var regions = map[string]int{
+	"_A": 10, // East England
+	"_B": 9,  // East Midlands
+	"_C": 13, // London
+	"_D": 6,  // North Wales & Merseyside
+	"_E": 8,  // West Midlands
+	"_F": 4,  // North East England
+	"_G": 3,  // North West England
+	"_H": 12, // South England
+	"_J": 14, // South East England
+	"_K": 7,  // South Wales
+	"_L": 11, // South West England
+	"_M": 5,  // Yorkshire
+	"_N": 2,  // South Scotland
+	"_P": 1,  // North Scotland
+}
+
+// RegionID returns the Carbon Intensity API region of a GSP group, with or without its leading
+// underscore, false if there is none.
+func RegionID(gsp string) (int, bool) {
+	gsp = strings.ToUpper(strings.TrimSpace(gsp))
+	if !strings.HasPrefix(gsp, "_") {
+		gsp = "_" + gsp
+	}
+	id, ok := regions[gsp]
+	return id, ok
+}
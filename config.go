@@ -1,9 +1,11 @@
 package octopusenergy
 
 import (
-	"log"
+	"errors"
 	"net/http"
 	"os"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Config provides service configuration for client.
@@ -18,6 +20,21 @@ type Config struct {
 
 	// The HTTP client to use when sending requests. Defaults to `http.DefaultClient`.
 	HTTPClient *http.Client
+
+	// Middleware wrapped around every request, the first being the outermost.
+	Middleware []Middleware
+
+	// Receives an event for every request, at or above LogLevel. Credentials are always redacted.
+	Logger Logger
+
+	// The minimum level of events sent to Logger. Defaults to LogLevelDebug.
+	LogLevel LogLevel
+
+	// Creates the spans traced for every request. Defaults to the global TracerProvider.
+	TracerProvider trace.TracerProvider
+
+	// The first error from a builder method, returned by every request of a client using the config.
+	err error
 }
 
 // NewConfig returns a new Config pointer that can be chained with builder
@@ -37,14 +54,15 @@ func (c *Config) WithApiKey(apiKey string) *Config {
 }
 
 // WithApiKeyFromEnvironments sets a config ApiKey value from environments valuable
-// returning a Config pointer for chaining.
+// returning a Config pointer for chaining. If the environment variable is missing or blank
+// Err returns an error, as will every request made by a client using the config.
 func (c *Config) WithApiKeyFromEnvironments() *Config {
 	apiKey, ok := os.LookupEnv(apiKeyEnvKey)
 	if !ok {
-		log.Fatalln("could not find api key in environment variable 'OCTOPUS_ENERGY_API_KEY'")
+		return c.setErr(errors.New("could not find api key in environment variable 'OCTOPUS_ENERGY_API_KEY'"))
 	}
 	if apiKey == "" {
-		log.Fatalln("the api key in environment variable 'OCTOPUS_ENERGY_API_KEY' is blank")
+		return c.setErr(errors.New("the api key in environment variable 'OCTOPUS_ENERGY_API_KEY' is blank"))
 	}
 
 	c.ApiKey = &apiKey
@@ -53,6 +71,9 @@ func (c *Config) WithApiKeyFromEnvironments() *Config {
 
 // WithEndpoint sets a config Endpoint value returning a Config pointer for chaining.
 func (c *Config) WithEndpoint(endpoint string) *Config {
+	if _, err := parseEndpoint(endpoint); err != nil {
+		c.setErr(err)
+	}
 	c.Endpoint = &endpoint
 	return c
 }
@@ -62,3 +83,34 @@ func (c *Config) WithHTTPClient(HTTPClient http.Client) *Config {
 	c.HTTPClient = &HTTPClient
 	return c
 }
+
+// WithMiddleware adds middleware to the config returning a Config pointer for chaining.
+func (c *Config) WithMiddleware(middleware ...Middleware) *Config {
+	c.Middleware = append(c.Middleware, middleware...)
+	return c
+}
+
+// WithLogger sets a config Logger and the minimum level it receives, returning a Config pointer for chaining.
+func (c *Config) WithLogger(logger Logger, level LogLevel) *Config {
+	c.Logger = logger
+	c.LogLevel = level
+	return c
+}
+
+// WithTracerProvider sets a config TracerProvider value returning a Config pointer for chaining.
+func (c *Config) WithTracerProvider(tp trace.TracerProvider) *Config {
+	c.TracerProvider = tp
+	return c
+}
+
+// Err returns the first error from a builder method, or nil if the config is valid.
+func (c *Config) Err() error {
+	return c.err
+}
+
+func (c *Config) setErr(err error) *Config {
+	if c.err == nil {
+		c.err = err
+	}
+	return c
+}
@@ -82,7 +82,15 @@ func (s *ConsumptionService) GetWithContext(ctx context.Context, options *Consum
 	}
 
 	res := ConsumptionGetOutput{}
-	if err := s.client.sendRequest(req, true, &res); err != nil {
+	op := Operation{
+		Service:      "Consumption",
+		Method:       "Get",
+		HTTPMethod:   http.MethodGet,
+		PathTemplate: "/v1/{fuel_type}-meter-points/{mpn}/meters/{serial_number}/consumption",
+		AuthRequired: true,
+		FuelType:     options.FuelType.String(),
+	}
+	if err := s.client.sendRequest(op, req, &res); err != nil {
 		return nil, err
 	}
 
@@ -96,17 +104,20 @@ func (s *ConsumptionService) GetPages(options *ConsumptionGetOptions) (*Consumpt
 
 // GetPagesWithContext same as GetPages except it takes a Context.
 func (s *ConsumptionService) GetPagesWithContext(ctx context.Context, options *ConsumptionGetOptions) (*ConsumptionGetOutput, error) {
+	ctx, span := s.client.startPagesSpan(ctx, Operation{Service: "Consumption", Method: "GetPages", PathTemplate: "/v1/{fuel_type}-meter-points/{mpn}/meters/{serial_number}/consumption", FuelType: options.FuelType.String()})
+	defer span.End()
+
 	options.PageSize = Int(1500)
 	options.Page = nil
 
 	fullResp := ConsumptionGetOutput{}
 	var lastPage bool
-	var i int
+	i := 1
 
 	for !lastPage {
 		page, err := s.GetWithContext(ctx, options)
 		if err != nil {
-			return nil, err
+			return nil, spanError(span, err)
 		}
 		fullResp.Count = page.Count
 		fullResp.Results = append(fullResp.Results, page.Results...)
@@ -117,5 +128,6 @@ func (s *ConsumptionService) GetPagesWithContext(ctx context.Context, options *C
 		options.Page = Int(i)
 	}
 
+	endPagesSpan(span, len(fullResp.Results))
 	return &fullResp, nil
 }
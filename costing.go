@@ -0,0 +1,98 @@
+package octopusenergy
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ConsumptionInterval is a single consumption result with its interval parsed.
+type ConsumptionInterval struct {
+	Start       time.Time
+	End         time.Time
+	Consumption float64
+}
+
+// Intervals parses the results into ConsumptionIntervals, ordered by start time.
+func (o *ConsumptionGetOutput) Intervals() ([]ConsumptionInterval, error) {
+	intervals := make([]ConsumptionInterval, 0, len(o.Results))
+	for _, r := range o.Results {
+		start, err := time.Parse(time.RFC3339, r.IntervalStart)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval start: %w", err)
+		}
+		end, err := time.Parse(time.RFC3339, r.IntervalEnd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval end: %w", err)
+		}
+		intervals = append(intervals, ConsumptionInterval{Start: start, End: end, Consumption: r.Consumption})
+	}
+
+	sort.SliceStable(intervals, func(i, j int) bool {
+		return intervals[i].Start.Before(intervals[j].Start)
+	})
+	return intervals, nil
+}
+
+// Cost is the result of pricing consumption against unit rates. Costs are in pence.
+type Cost struct {
+	// Total consumption priced, in kWh (or m³ for SMETS1 gas meters).
+	Consumption float64
+
+	CostExcVat float64
+	CostIncVat float64
+
+	// Intervals that had no unit rate covering their start time and so were not priced.
+	Unpriced []ConsumptionInterval
+}
+
+// CostConsumption prices each interval at the unit rate active at its start. Standing charges are
+// not included. Unit rates can be in any order, as returned by TariffChargeService.
+func CostConsumption(intervals []ConsumptionInterval, unitRates []TariffCharge) Cost {
+	rates := NewChargeIndex(unitRates)
+
+	var cost Cost
+	for _, in := range intervals {
+		rate, ok := rates.At(in.Start)
+		if !ok {
+			cost.Unpriced = append(cost.Unpriced, in)
+			continue
+		}
+		cost.Consumption += in.Consumption
+		cost.CostExcVat += in.Consumption * rate.ValueExcVat
+		cost.CostIncVat += in.Consumption * rate.ValueIncVat
+	}
+	return cost
+}
+
+// ChargeAt returns the charge active at t. Charges can be in any order.
+func ChargeAt(charges []TariffCharge, t time.Time) (TariffCharge, bool) {
+	return NewChargeIndex(charges).At(t)
+}
+
+// ChargeIndex is charges ordered by ValidFrom, to look up the charge active at many times.
+type ChargeIndex []TariffCharge
+
+// NewChargeIndex returns a ChargeIndex of a copy of charges, which can be in any order.
+func NewChargeIndex(charges []TariffCharge) ChargeIndex {
+	sorted := make(ChargeIndex, len(charges))
+	copy(sorted, charges)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ValidFrom.Before(sorted[j].ValidFrom)
+	})
+	return sorted
+}
+
+// At returns the charge active at t, false if there is none.
+func (c ChargeIndex) At(t time.Time) (TariffCharge, bool) {
+	i := sort.Search(len(c), func(i int) bool {
+		return c[i].ValidFrom.After(t)
+	}) - 1
+	if i < 0 {
+		return TariffCharge{}, false
+	}
+	if !c[i].ValidTo.IsZero() && !t.Before(c[i].ValidTo) {
+		return TariffCharge{}, false
+	}
+	return c[i], true
+}
@@ -0,0 +1,40 @@
+package octopusenergy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+func TestChargeIndex(t *testing.T) {
+	day := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	charges := []octopusenergy.TariffCharge{
+		{ValueIncVat: 30, ValidFrom: day.Add(16 * time.Hour), ValidTo: day.Add(19 * time.Hour)},
+		{ValueIncVat: 15, ValidFrom: day.Add(20 * time.Hour)},
+		{ValueIncVat: 10, ValidFrom: day, ValidTo: day.Add(16 * time.Hour)},
+	}
+	index := octopusenergy.NewChargeIndex(charges)
+
+	if charges[0].ValueIncVat != 30 {
+		t.Error("expected the charges passed in not to be reordered")
+	}
+
+	tests := []struct {
+		at    time.Time
+		price float64
+		ok    bool
+	}{
+		{at: day.Add(-time.Minute)},
+		{at: day, price: 10, ok: true},
+		{at: day.Add(16 * time.Hour), price: 30, ok: true},
+		{at: day.Add(19 * time.Hour)},
+		{at: day.AddDate(1, 0, 0), price: 15, ok: true},
+	}
+	for _, tc := range tests {
+		c, ok := index.At(tc.at)
+		if ok != tc.ok || c.ValueIncVat != tc.price {
+			t.Errorf("at %s: expected %v %v, got %v %v", tc.at.Format(time.Kitchen), tc.price, tc.ok, c.ValueIncVat, ok)
+		}
+	}
+}
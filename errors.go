@@ -1,6 +1,33 @@
 package octopusenergy
 
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
 // errorResponse is the returned body when API error accrues
 type errorResponse struct {
 	Detail string `json:"detail"`
 }
+
+// APIError is returned when the API responds with an error status code.
+type APIError struct {
+	StatusCode int
+
+	// The detail message of the response, blank if it had none.
+	Detail string
+}
+
+func (e *APIError) Error() string {
+	if e.Detail != "" {
+		return e.Detail
+	}
+	return fmt.Sprintf("unknown error, status code: %d", e.StatusCode)
+}
+
+// isNotFound reports whether err is a 404 response from the API.
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+func main() {
+	profileName := flag.String("profile", "", "config profile to use, defaults to the file's default profile")
+	confidence := flag.Float64("confidence", 0.8, "how likely the bill is to fall within the range")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	options := &octopusenergy.LoadProfileOptions{}
+	if *profileName != "" {
+		options.Profile = profileName
+	}
+	profile, err := octopusenergy.LoadProfile(options)
+	if err != nil {
+		log.Fatalf("failed to load profile: %s", err.Error())
+	}
+	if profile.AccountNumber == "" {
+		log.Fatal("no account number, set OCTOPUS_ENERGY_ACCOUNT_NUMBER or account_number in the profile")
+	}
+
+	client := octopusenergy.NewClient(profile.Config())
+	household, err := client.Household.GetWithContext(ctx, &octopusenergy.HouseholdGetOptions{AccountNumber: profile.AccountNumber})
+	if err != nil {
+		log.Fatalf("failed to get household: %s", err.Error())
+	}
+
+	for _, p := range household.Properties {
+		for _, mp := range p.MeterPoints {
+			if mp.IsExport || mp.ConsumptionOptions() == nil {
+				continue
+			}
+
+			projection, err := client.Household.GetBillProjectionWithContext(ctx, &octopusenergy.BillProjectionGetOptions{
+				MeterPoint: mp,
+				Confidence: *confidence,
+			})
+			if err != nil {
+				log.Fatalf("failed to project %s bill for %s: %s", mp.FuelType, mp.MPN, err.Error())
+			}
+
+			fmt.Printf("%s %s, %s to %s\n", mp.FuelType, mp.MPN, projection.From.Format("2 Jan"), projection.To.Add(-time.Nanosecond).Format("2 Jan"))
+			fmt.Printf("  to %s: %.1f kWh, £%.2f\n", projection.DataTo.Format("2 Jan 15:04"), projection.ConsumptionToDate, projection.CostToDateIncVat/100)
+			fmt.Printf("  projected: %.0f kWh (%.0f to %.0f), £%.2f (£%.2f to £%.2f)\n",
+				projection.Consumption, projection.ConsumptionLow, projection.ConsumptionHigh,
+				projection.CostIncVat/100, projection.CostLowIncVat/100, projection.CostHighIncVat/100)
+			if projection.EstimatedRates {
+				fmt.Println("  some rates are not yet published and were estimated from the last week")
+			}
+		}
+	}
+}
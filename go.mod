@@ -3,6 +3,7 @@ module github.com/danopstech/octopusenergy
 go 1.16
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/golangci/golangci-lint v1.50.1
 	github.com/google/go-querystring v1.1.0
 	github.com/hashicorp/errwrap v1.1.0 // indirect
@@ -13,8 +14,11 @@ require (
 	github.com/rivo/uniseg v0.4.2 // indirect
 	github.com/spf13/cobra v1.6.1 // indirect
 	github.com/stretchr/testify v1.8.1 // indirect
+	go.opentelemetry.io/otel v1.7.0
+	go.opentelemetry.io/otel/trace v1.7.0
 	golang.org/x/text v0.5.0 // indirect
 	golang.org/x/tools v0.2.0
 	google.golang.org/protobuf v1.28.1 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,294 @@
+package octopusenergy
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	graphQLPath = "v1/graphql/"
+
+	// refresh the token this long before it actually expires, to allow for clock skew and slow requests.
+	krakenTokenLeeway = 5 * time.Minute
+
+	// Kraken error code returned when a JWT has expired.
+	krakenErrorCodeTokenExpired = "KT-CT-1124"
+)
+
+// ErrNoApiKey is returned when a request needs an API key but none was configured.
+var ErrNoApiKey = errors.New("an api key is required for this request")
+
+// GraphQLService handles communication with the Octopus Kraken GraphQL API. The JWT the API
+// requires is obtained from Config.ApiKey, cached and refreshed before it expires.
+type GraphQLService service
+
+// GraphQLError is a single error returned in a GraphQL response.
+type GraphQLError struct {
+	Message    string        `json:"message"`
+	Path       []interface{} `json:"path"`
+	Extensions struct {
+		ErrorType        string `json:"errorType"`
+		ErrorCode        string `json:"errorCode"`
+		ErrorDescription string `json:"errorDescription"`
+	} `json:"extensions"`
+}
+
+func (e *GraphQLError) Error() string {
+	if e.Extensions.ErrorCode != "" {
+		return fmt.Sprintf("%s (%s)", e.Message, e.Extensions.ErrorCode)
+	}
+	return e.Message
+}
+
+// GraphQLErrors is returned when a GraphQL response contains one or more errors.
+type GraphQLErrors []*GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
+
+// HasCode reports whether any of the errors has the given Kraken error code, example "KT-CT-1124".
+func (e GraphQLErrors) HasCode(code string) bool {
+	for _, err := range e {
+		if err.Extensions.ErrorCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+// krakenFloat decodes numbers that Kraken returns either as JSON numbers or as strings.
+type krakenFloat float64
+
+func (f *krakenFloat) UnmarshalJSON(b []byte) error {
+	v := string(bytes.Trim(b, `"`))
+	if v == "" || v == "null" {
+		*f = 0
+		return nil
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s: %w", b, err)
+	}
+	*f = krakenFloat(n)
+	return nil
+}
+
+// krakenToken caches the JWT used to authenticate GraphQL requests.
+type krakenToken struct {
+	mu             sync.Mutex
+	token          string
+	expiresAt      time.Time
+	refreshToken   string
+	refreshExpires time.Time
+}
+
+// Query runs a GraphQL query or mutation and decodes the "data" member of the response into castTo.
+func (s *GraphQLService) Query(query string, variables map[string]interface{}, castTo interface{}) error {
+	return s.QueryWithContext(context.Background(), query, variables, castTo)
+}
+
+// QueryWithContext same as Query except it takes a Context.
+func (s *GraphQLService) QueryWithContext(ctx context.Context, query string, variables map[string]interface{}, castTo interface{}) error {
+	err := s.query(ctx, query, variables, castTo, true)
+
+	var gqlErrs GraphQLErrors
+	if errors.As(err, &gqlErrs) && gqlErrs.HasCode(krakenErrorCodeTokenExpired) {
+		// the server disagrees with our idea of when the token expires, start again
+		s.client.krakenToken.invalidate()
+		err = s.query(withAttempt(ctx, 2), query, variables, castTo, true)
+	}
+
+	return err
+}
+
+func (s *GraphQLService) query(ctx context.Context, query string, variables map[string]interface{}, castTo interface{}, authed bool) error {
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	rel := &url.URL{Path: graphQLPath}
+	u := s.client.BaseURL.ResolveReference(rel)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if authed {
+		token, err := s.token(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", token)
+	}
+
+	res := graphQLResponse{}
+	if err := s.client.sendRequest(graphQLOperation(query, authed), req, &res); err != nil {
+		return err
+	}
+
+	if len(res.Errors) > 0 {
+		return res.Errors
+	}
+
+	if castTo == nil || len(res.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(res.Data, castTo)
+}
+
+const obtainKrakenTokenMutation = `mutation ObtainKrakenToken($input: ObtainJSONWebTokenInput!) {
+  obtainKrakenToken(input: $input) {
+    token
+    refreshToken
+    refreshExpiresIn
+    payload
+  }
+}`
+
+// token returns a valid JWT, obtaining a new one if the cached one is missing or about to expire.
+func (s *GraphQLService) token(ctx context.Context) (string, error) {
+	t := &s.client.krakenToken
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if t.token != "" && now.Add(krakenTokenLeeway).Before(t.expiresAt) {
+		return t.token, nil
+	}
+
+	input := map[string]interface{}{}
+	switch {
+	case t.refreshToken != "" && now.Add(krakenTokenLeeway).Before(t.refreshExpires):
+		input["refreshToken"] = t.refreshToken
+	case s.client.apiKey != "":
+		input["APIKey"] = s.client.apiKey
+	default:
+		return "", ErrNoApiKey
+	}
+
+	var res struct {
+		ObtainKrakenToken struct {
+			Token            string `json:"token"`
+			RefreshToken     string `json:"refreshToken"`
+			RefreshExpiresIn int64  `json:"refreshExpiresIn"`
+			Payload          struct {
+				Exp int64 `json:"exp"`
+			} `json:"payload"`
+		} `json:"obtainKrakenToken"`
+	}
+
+	err := s.query(ctx, obtainKrakenTokenMutation, map[string]interface{}{"input": input}, &res, false)
+	if err != nil && input["refreshToken"] != nil && s.client.apiKey != "" {
+		// the refresh token may have been revoked, fall back to the api key
+		t.refreshToken = ""
+		input = map[string]interface{}{"APIKey": s.client.apiKey}
+		err = s.query(ctx, obtainKrakenTokenMutation, map[string]interface{}{"input": input}, &res, false)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain kraken token: %w", err)
+	}
+
+	out := res.ObtainKrakenToken
+	if out.Token == "" {
+		return "", errors.New("failed to obtain kraken token: empty token returned")
+	}
+
+	t.token = out.Token
+	t.expiresAt = jwtExpiry(out.Token, out.Payload.Exp, now)
+	if out.RefreshToken != "" {
+		t.refreshToken = out.RefreshToken
+		t.refreshExpires = time.Unix(out.RefreshExpiresIn, 0)
+	}
+
+	return t.token, nil
+}
+
+func (t *krakenToken) invalidate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+}
+
+// jwtExpiry works out when a token expires, preferring the payload returned alongside it, then the
+// token's own claims, and finally assuming the one hour lifetime Kraken documents.
+func jwtExpiry(token string, payloadExp int64, now time.Time) time.Time {
+	if payloadExp > 0 {
+		return time.Unix(payloadExp, 0)
+	}
+
+	if parts := strings.Split(token, "."); len(parts) == 3 {
+		if b, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+			var claims struct {
+				Exp int64 `json:"exp"`
+			}
+			if json.Unmarshal(b, &claims) == nil && claims.Exp > 0 {
+				return time.Unix(claims.Exp, 0)
+			}
+		}
+	}
+
+	return now.Add(time.Hour)
+}
+
+// GraphQLViewerOutput is the returned struct from Viewer.
+type GraphQLViewerOutput struct {
+	Viewer struct {
+		PreferredName string `json:"preferredName"`
+		Email         string `json:"email"`
+		Accounts      []struct {
+			Number string `json:"number"`
+		} `json:"accounts"`
+	} `json:"viewer"`
+}
+
+const viewerQuery = `query Viewer {
+  viewer {
+    preferredName
+    email
+    accounts {
+      number
+    }
+  }
+}`
+
+// Viewer retrieves the authenticated user and the account numbers they can access.
+func (s *GraphQLService) Viewer() (*GraphQLViewerOutput, error) {
+	return s.ViewerWithContext(context.Background())
+}
+
+// ViewerWithContext same as Viewer except it takes a Context.
+func (s *GraphQLService) ViewerWithContext(ctx context.Context) (*GraphQLViewerOutput, error) {
+	res := GraphQLViewerOutput{}
+	if err := s.QueryWithContext(ctx, viewerQuery, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
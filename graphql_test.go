@@ -0,0 +1,126 @@
+package octopusenergy_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+type fakeKraken struct {
+	tokensIssued int
+	expireNext   bool
+	lastAuth     string
+}
+
+func (f *fakeKraken) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	switch {
+	case strings.Contains(req.Query, "obtainKrakenToken"):
+		input := req.Variables["input"].(map[string]interface{})
+		if input["APIKey"] != "sk_test" && input["refreshToken"] == nil {
+			fmt.Fprint(w, `{"errors":[{"message":"Invalid data.","extensions":{"errorCode":"KT-CT-1139"}}]}`)
+			return
+		}
+		f.tokensIssued++
+		fmt.Fprintf(w, `{"data":{"obtainKrakenToken":{"token":"jwt-%d","refreshToken":"refresh","refreshExpiresIn":%d,"payload":{"exp":%d}}}}`,
+			f.tokensIssued, time.Now().Add(7*24*time.Hour).Unix(), time.Now().Add(time.Hour).Unix())
+	case f.expireNext:
+		f.expireNext = false
+		fmt.Fprint(w, `{"errors":[{"message":"Signature of the JWT has expired.","extensions":{"errorCode":"KT-CT-1124"}}]}`)
+	default:
+		f.lastAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"data":{"viewer":{"preferredName":"Dan","accounts":[{"number":"A-AAAA1111"}]}}}`)
+	}
+}
+
+func TestGraphQLViewer(t *testing.T) {
+	kraken := &fakeKraken{}
+	server := httptest.NewServer(kraken)
+	defer server.Close()
+
+	client := octopusenergy.NewClient(octopusenergy.NewConfig().
+		WithApiKey("sk_test").
+		WithEndpoint(server.URL),
+	)
+
+	for i := 0; i < 2; i++ {
+		viewer, err := client.GraphQL.Viewer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := viewer.Viewer.Accounts[0].Number; got != "A-AAAA1111" {
+			t.Errorf("unexpected account number %q", got)
+		}
+	}
+
+	if kraken.tokensIssued != 1 {
+		t.Errorf("expected token to be cached, %d tokens issued", kraken.tokensIssued)
+	}
+	if kraken.lastAuth != "jwt-1" {
+		t.Errorf("unexpected authorization header %q", kraken.lastAuth)
+	}
+
+	// a server side expiry forces a new token and the query is retried
+	kraken.expireNext = true
+	if _, err := client.GraphQL.Viewer(); err != nil {
+		t.Fatal(err)
+	}
+	if kraken.tokensIssued != 2 || kraken.lastAuth != "jwt-2" {
+		t.Errorf("expected token refresh, %d tokens issued, last auth %q", kraken.tokensIssued, kraken.lastAuth)
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(&fakeKraken{})
+	defer server.Close()
+
+	client := octopusenergy.NewClient(octopusenergy.NewConfig().
+		WithApiKey("wrong").
+		WithEndpoint(server.URL),
+	)
+
+	_, err := client.GraphQL.Viewer()
+	var gqlErrs octopusenergy.GraphQLErrors
+	if !errors.As(err, &gqlErrs) {
+		t.Fatalf("expected GraphQLErrors, got %v", err)
+	}
+	if !gqlErrs.HasCode("KT-CT-1139") {
+		t.Errorf("expected error code KT-CT-1139, got %v", gqlErrs)
+	}
+
+	noKey := octopusenergy.NewClient(octopusenergy.NewConfig().WithEndpoint(server.URL))
+	if _, err := noKey.GraphQL.Viewer(); !errors.Is(err, octopusenergy.ErrNoApiKey) {
+		t.Errorf("expected ErrNoApiKey, got %v", err)
+	}
+}
+
+// krakenGraphQL is a GraphQL handler for octopustest.Server. It issues tokens and answers every
+// other query with the response body returned by the function.
+type krakenGraphQL func(query string, variables map[string]interface{}) string
+
+func (k krakenGraphQL) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	if strings.Contains(req.Query, "obtainKrakenToken") {
+		fmt.Fprintf(w, `{"data":{"obtainKrakenToken":{"token":"jwt","refreshToken":"refresh","refreshExpiresIn":%d,"payload":{"exp":%d}}}}`,
+			time.Now().Add(7*24*time.Hour).Unix(), time.Now().Add(time.Hour).Unix())
+		return
+	}
+	fmt.Fprint(w, k(req.Query, req.Variables))
+}
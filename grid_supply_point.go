@@ -44,7 +44,13 @@ func (s *GridSupplyPointService) GetWithContext(ctx context.Context, options *Gr
 	}
 
 	res := GridSupplyPointGetOutput{}
-	if err := s.client.sendRequest(req, false, &res); err != nil {
+	op := Operation{
+		Service:      "GridSupplyPoint",
+		Method:       "Get",
+		HTTPMethod:   http.MethodGet,
+		PathTemplate: "/v1/industry/grid-supply-points",
+	}
+	if err := s.client.sendRequest(op, req, &res); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,274 @@
+package octopusenergy
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HouseholdService discovers the meters and tariffs of an account, combining the account, meter point,
+// grid supply point and consumption endpoints.
+type HouseholdService service
+
+// HouseholdGetOptions is the options for Get.
+type HouseholdGetOptions struct {
+	// The octopus account number to discover.
+	AccountNumber string
+
+	// The time properties, agreements and meters are resolved at. Defaults to now.
+	At *time.Time
+
+	// Also include properties the customer has moved out of.
+	IncludePastProperties bool
+}
+
+// Household is everything needed to fetch the consumption and rates of an account.
+type Household struct {
+	AccountNumber string
+	Properties    []HouseholdProperty
+}
+
+// HouseholdProperty is a property of a Household.
+type HouseholdProperty struct {
+	Property
+
+	// The grid supply point group ID of the property, example "_C". Blank if it could not be found.
+	Region string
+
+	MeterPoints []HouseholdMeterPoint
+}
+
+// HouseholdMeterPoint is an electricity or gas meter point of a property.
+type HouseholdMeterPoint struct {
+	FuelType FuelType
+
+	// The meter point's MPAN for electricity, or MPRN for gas.
+	MPN string
+
+	// Whether the meter point measures electricity exported to the grid, rather than imported.
+	IsExport bool
+
+	// Every meter installed at the meter point, current and past.
+	Meters []Meter
+
+	// The serial number of the meter with the most recent consumption data. Blank if no meter has any,
+	// which is usual for meters that are not smart.
+	ActiveSerialNumber string
+
+	// The agreement in force at the time asked for, nil if there is none.
+	Agreement *Agreement
+
+	// Every agreement of the meter point, oldest first.
+	Agreements []Agreement
+}
+
+// Get walks an account and returns its current properties, meter points, the serial number reporting
+// consumption for each, their active agreements and the property's region.
+func (s *HouseholdService) Get(options *HouseholdGetOptions) (*Household, error) {
+	return s.GetWithContext(context.Background(), options)
+}
+
+// GetWithContext same as Get except it takes a Context.
+func (s *HouseholdService) GetWithContext(ctx context.Context, options *HouseholdGetOptions) (*Household, error) {
+	at := time.Now()
+	if options.At != nil {
+		at = *options.At
+	}
+
+	account, err := s.client.Account.GetWithContext(ctx, &AccountGetOptions{AccountNumber: options.AccountNumber})
+	if err != nil {
+		return nil, err
+	}
+
+	household := Household{AccountNumber: account.Number}
+	for _, property := range account.Properties {
+		if !options.IncludePastProperties && (property.MovedInAt.After(at) || property.MovedOutAt != nil && !property.MovedOutAt.After(at)) {
+			continue
+		}
+
+		hp := HouseholdProperty{Property: property}
+		for _, mp := range property.ElectricityMeterPoints {
+			hp.MeterPoints = append(hp.MeterPoints, newHouseholdMeterPoint(FuelTypeElectricity, mp.MPAN, mp.Meters, mp.Agreements, at))
+		}
+		for _, mp := range property.GasMeterPoints {
+			hp.MeterPoints = append(hp.MeterPoints, newHouseholdMeterPoint(FuelTypeGas, mp.MPRN, mp.Meters, mp.Agreements, at))
+		}
+
+		for i := range hp.MeterPoints {
+			serial, err := s.activeSerialNumber(ctx, &hp.MeterPoints[i])
+			if err != nil {
+				return nil, err
+			}
+			hp.MeterPoints[i].ActiveSerialNumber = serial
+		}
+
+		if hp.Region, err = s.region(ctx, hp); err != nil {
+			return nil, err
+		}
+
+		household.Properties = append(household.Properties, hp)
+	}
+
+	return &household, nil
+}
+
+func newHouseholdMeterPoint(fuelType FuelType, mpn string, meters []Meter, agreements []Agreement, at time.Time) HouseholdMeterPoint {
+	timeline := NewAgreementTimeline(agreements)
+	mp := HouseholdMeterPoint{
+		FuelType:   fuelType,
+		MPN:        mpn,
+		Meters:     meters,
+		Agreements: timeline,
+	}
+	if a, ok := timeline.At(at); ok {
+		mp.Agreement = &a
+	}
+	for _, a := range timeline {
+		if isExportTariff(a.TariffCode) {
+			mp.IsExport = true
+		}
+	}
+	return mp
+}
+
+// activeSerialNumber finds which of the meter point's meters has the most recent consumption data.
+func (s *HouseholdService) activeSerialNumber(ctx context.Context, mp *HouseholdMeterPoint) (string, error) {
+	var serial string
+	var latest time.Time
+	for _, m := range mp.Meters {
+		if m.SerialNumber == "" {
+			continue
+		}
+		res, err := s.client.Consumption.GetWithContext(ctx, &ConsumptionGetOptions{
+			MPN:          mp.MPN,
+			SerialNumber: m.SerialNumber,
+			FuelType:     mp.FuelType,
+			PageSize:     Int(1),
+		})
+		if isNotFound(err) {
+			// meters that have never sent data are not found
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if len(res.Results) == 0 {
+			continue
+		}
+		end, err := time.Parse(time.RFC3339, res.Results[0].IntervalEnd)
+		if err != nil {
+			return "", err
+		}
+		if serial == "" || end.After(latest) {
+			serial, latest = m.SerialNumber, end
+		}
+	}
+	return serial, nil
+}
+
+// region finds the grid supply point group of a property, from its electricity meter point, then its
+// postcode, then the region letter at the end of its tariff codes.
+func (s *HouseholdService) region(ctx context.Context, hp HouseholdProperty) (string, error) {
+	for _, mp := range hp.MeterPoints {
+		if mp.FuelType != FuelTypeElectricity || mp.IsExport {
+			continue
+		}
+		res, err := s.client.MeterPoint.GetWithContext(ctx, &MeterPointGetOptions{MPAN: mp.MPN})
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if res.GSP != "" {
+			return res.GSP, nil
+		}
+	}
+
+	if hp.Postcode != "" {
+		res, err := s.client.GridSupplyPoint.GetWithContext(ctx, &GridSupplyPointGetOptions{Postcode: String(hp.Postcode)})
+		if err != nil {
+			return "", err
+		}
+		if len(res.Results) == 1 {
+			return res.Results[0].GroupID, nil
+		}
+	}
+
+	for _, mp := range hp.MeterPoints {
+		if mp.Agreement != nil {
+			if region := TariffRegion(mp.Agreement.TariffCode); region != "" {
+				return region, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// MeterPoints returns the meter points of every property with the given fuel type.
+func (h *Household) MeterPoints(fuelType FuelType) []HouseholdMeterPoint {
+	var out []HouseholdMeterPoint
+	for _, p := range h.Properties {
+		for _, mp := range p.MeterPoints {
+			if mp.FuelType == fuelType {
+				out = append(out, mp)
+			}
+		}
+	}
+	return out
+}
+
+// ConsumptionOptions returns options to fetch the consumption of the meter point's active meter, nil
+// if it has none.
+func (mp HouseholdMeterPoint) ConsumptionOptions() *ConsumptionGetOptions {
+	if mp.ActiveSerialNumber == "" {
+		return nil
+	}
+	return &ConsumptionGetOptions{MPN: mp.MPN, SerialNumber: mp.ActiveSerialNumber, FuelType: mp.FuelType}
+}
+
+// TariffChargesOptions returns options to fetch a rate of the meter point's current tariff, nil if it
+// has no agreement in force.
+func (mp HouseholdMeterPoint) TariffChargesOptions(rate Rate) *TariffChargesGetOptions {
+	if mp.Agreement == nil {
+		return nil
+	}
+	return &TariffChargesGetOptions{
+		ProductCode: TariffProductCode(mp.Agreement.TariffCode),
+		TariffCode:  mp.Agreement.TariffCode,
+		FuelType:    mp.FuelType,
+		Rate:        rate,
+	}
+}
+
+// TariffProductCode returns the product code of a tariff code, example "AGILE-18-02-21" for
+// "E-1R-AGILE-18-02-21-C".
+func TariffProductCode(tariffCode string) string {
+	parts := strings.Split(tariffCode, "-")
+	if len(parts) < 4 {
+		return tariffCode
+	}
+	return strings.Join(parts[2:len(parts)-1], "-")
+}
+
+// TariffRegion returns the grid supply point group a tariff code is for, example "_C" for
+// "E-1R-AGILE-18-02-21-C", or blank if the code does not end in a region letter.
+func TariffRegion(tariffCode string) string {
+	i := strings.LastIndex(tariffCode, "-")
+	if i < 0 || len(tariffCode)-i != 2 {
+		return ""
+	}
+	return "_" + tariffCode[i+1:]
+}
+
+func isExportTariff(tariffCode string) bool {
+	return strings.Contains(tariffCode, "OUTGOING") || strings.Contains(tariffCode, "EXPORT")
+}
+
+// sortedAgreements returns a copy of agreements ordered by when they start.
+func sortedAgreements(agreements []Agreement) []Agreement {
+	out := append([]Agreement(nil), agreements...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ValidFrom.Before(out[j].ValidFrom) })
+	return out
+}
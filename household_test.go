@@ -0,0 +1,122 @@
+package octopusenergy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestHouseholdGet(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	movedIn := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	switched := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	movedOut := movedIn
+
+	srv.AddAccount(octopusenergy.AccountGetOutput{
+		Number: "A-AAAA1111",
+		Properties: []octopusenergy.Property{
+			{
+				ID:        1,
+				MovedInAt: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+				// moved out before moving into the current property
+				MovedOutAt: &movedOut,
+				Postcode:   "AB1 2CD",
+			},
+			{
+				ID:        2,
+				MovedInAt: movedIn,
+				Postcode:  "SW1A 1AA",
+				ElectricityMeterPoints: []octopusenergy.ElectricityMeterPoints{
+					{
+						MPAN: "1111111111111",
+						Meters: []octopusenergy.Meter{
+							{SerialNumber: "OLD1111111"},
+							{SerialNumber: "NEW2222222", Registers: []octopusenergy.MeterRegister{{Identifier: "1", IsSettlementRegister: true}}},
+						},
+						Agreements: []octopusenergy.Agreement{
+							{TariffCode: "E-1R-AGILE-18-02-21-C", ValidFrom: switched},
+							{TariffCode: "E-1R-VAR-19-04-12-C", ValidFrom: movedIn, ValidTo: &switched},
+						},
+					},
+					{
+						MPAN:       "3333333333333",
+						Agreements: []octopusenergy.Agreement{{TariffCode: "E-1R-AGILE-OUTGOING-19-05-13-C", ValidFrom: movedIn}},
+					},
+				},
+				GasMeterPoints: []octopusenergy.GasMeterPoints{
+					{
+						MPRN:       "2222222222",
+						Meters:     []octopusenergy.Meter{{SerialNumber: "G4A0000000"}},
+						Agreements: []octopusenergy.Agreement{{TariffCode: "G-1R-VAR-19-04-12-C", ValidFrom: movedIn}},
+					},
+				},
+			},
+		},
+	})
+	srv.AddMeterPoint(octopusenergy.MeterPointGetOutput{MPAN: "1111111111111", GSP: "_C"})
+
+	day := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	old := octopusenergy.ConsumptionInterval{Start: day.Add(-24 * time.Hour), End: day.Add(-23*time.Hour - 30*time.Minute), Consumption: 1}
+	current := octopusenergy.ConsumptionInterval{Start: day, End: day.Add(30 * time.Minute), Consumption: 1}
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111111", "OLD1111111", old)
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111111", "NEW2222222", old, current)
+
+	household, err := srv.Client().Household.Get(&octopusenergy.HouseholdGetOptions{AccountNumber: "A-AAAA1111", At: octopusenergy.Time(day)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(household.Properties) != 1 || household.Properties[0].ID != 2 {
+		t.Fatalf("expected only the current property, got %+v", household.Properties)
+	}
+	property := household.Properties[0]
+	if property.Region != "_C" {
+		t.Errorf("expected region _C, got %q", property.Region)
+	}
+
+	electricity := household.MeterPoints(octopusenergy.FuelTypeElectricity)
+	if len(electricity) != 2 {
+		t.Fatalf("expected import and export meter points, got %d", len(electricity))
+	}
+	imports := electricity[0]
+	if imports.IsExport || imports.ActiveSerialNumber != "NEW2222222" {
+		t.Errorf("expected the new meter to be active, got %+v", imports)
+	}
+	if imports.Agreement == nil || imports.Agreement.TariffCode != "E-1R-AGILE-18-02-21-C" || imports.Agreements[0].TariffCode != "E-1R-VAR-19-04-12-C" {
+		t.Errorf("unexpected agreements %+v", imports.Agreements)
+	}
+	if opts := imports.TariffChargesOptions(octopusenergy.RateStandardUnit); opts.ProductCode != "AGILE-18-02-21" {
+		t.Errorf("unexpected tariff charges options %+v", opts)
+	}
+	if opts := imports.ConsumptionOptions(); opts.SerialNumber != "NEW2222222" || opts.MPN != "1111111111111" {
+		t.Errorf("unexpected consumption options %+v", opts)
+	}
+	if !electricity[1].IsExport || electricity[1].ConsumptionOptions() != nil {
+		t.Errorf("expected an export meter point without consumption, got %+v", electricity[1])
+	}
+
+	gas := household.MeterPoints(octopusenergy.FuelTypeGas)
+	if len(gas) != 1 || gas[0].ActiveSerialNumber != "" || gas[0].Agreement == nil {
+		t.Errorf("unexpected gas meter points %+v", gas)
+	}
+}
+
+func TestTariffCodes(t *testing.T) {
+	for code, want := range map[string][2]string{
+		"E-1R-AGILE-18-02-21-C":          {"AGILE-18-02-21", "_C"},
+		"E-2R-VAR-19-04-12-P":            {"VAR-19-04-12", "_P"},
+		"G-1R-SILVER-FLEX-22-11-25-H":    {"SILVER-FLEX-22-11-25", "_H"},
+		"E-1R-AGILE-OUTGOING-19-05-13-A": {"AGILE-OUTGOING-19-05-13", "_A"},
+	} {
+		if got := octopusenergy.TariffProductCode(code); got != want[0] {
+			t.Errorf("%s: expected product %s, got %s", code, want[0], got)
+		}
+		if got := octopusenergy.TariffRegion(code); got != want[1] {
+			t.Errorf("%s: expected region %s, got %s", code, want[1], got)
+		}
+	}
+}
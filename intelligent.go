@@ -0,0 +1,239 @@
+package octopusenergy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// IntelligentService handles communication with the Intelligent Octopus related parts of the GraphQL API.
+type IntelligentService service
+
+// IntelligentDispatchesGetOptions is the options for GetDispatches.
+type IntelligentDispatchesGetOptions struct {
+	// The octopus account number the smart charging device belongs to.
+	AccountNumber string
+}
+
+// IntelligentDispatchesGetOutput is the returned struct from GetDispatches.
+type IntelligentDispatchesGetOutput struct {
+	// Smart charge slots scheduled for the future.
+	Planned []IntelligentDispatch `json:"plannedDispatches"`
+
+	// Smart charge slots which have happened, these are the ones billed at the off-peak rate.
+	Completed []IntelligentDispatch `json:"completedDispatches"`
+}
+
+// IntelligentDispatch is a single smart charging slot.
+type IntelligentDispatch struct {
+	Start time.Time
+
+	End time.Time
+
+	// Energy delivered in the slot, in kWh. Kraken reports charging as a negative number.
+	Delta float64
+
+	// What created the dispatch, example DispatchSourceSmartCharge or DispatchSourceBumpCharge.
+	Source string
+
+	// Where the charge happened, example DispatchLocationHome.
+	Location string
+}
+
+// Dispatch sources and locations reported by Kraken.
+const (
+	DispatchSourceSmartCharge = "smart-charge"
+	DispatchSourceBumpCharge  = "bump-charge"
+	DispatchLocationHome      = "AT_HOME"
+)
+
+// BilledOffPeak reports whether the dispatch is billed at the off-peak rate, which is only the case for
+// smart charging at home. Bump charges, asked for by the driver, and charging elsewhere are billed at
+// the normal rate. Dispatches without a source or location are taken to be smart charging at home.
+func (d IntelligentDispatch) BilledOffPeak() bool {
+	return (d.Source == "" || d.Source == DispatchSourceSmartCharge) && (d.Location == "" || d.Location == DispatchLocationHome)
+}
+
+type intelligentDispatchJSON struct {
+	StartDt string      `json:"startDt"`
+	EndDt   string      `json:"endDt"`
+	Delta   krakenFloat `json:"delta"`
+	Meta    struct {
+		Source   string `json:"source"`
+		Location string `json:"location"`
+	} `json:"meta"`
+}
+
+// UnmarshalJSON decodes a dispatch, Kraken returns the times in a non RFC3339
+// format and the delta as a string.
+func (d *IntelligentDispatch) UnmarshalJSON(b []byte) error {
+	var raw intelligentDispatchJSON
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	var err error
+	if d.Start, err = parseKrakenTime(raw.StartDt); err != nil {
+		return err
+	}
+	if d.End, err = parseKrakenTime(raw.EndDt); err != nil {
+		return err
+	}
+
+	d.Delta = float64(raw.Delta)
+	d.Source = raw.Meta.Source
+	d.Location = raw.Meta.Location
+	return nil
+}
+
+// MarshalJSON encodes a dispatch in the same shape it was received.
+func (d IntelligentDispatch) MarshalJSON() ([]byte, error) {
+	raw := intelligentDispatchJSON{
+		StartDt: d.Start.Format(time.RFC3339),
+		EndDt:   d.End.Format(time.RFC3339),
+		Delta:   krakenFloat(d.Delta),
+	}
+	raw.Meta.Source = d.Source
+	raw.Meta.Location = d.Location
+	return json.Marshal(raw)
+}
+
+var krakenTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05.999999Z07:00",
+}
+
+func parseKrakenTime(v string) (time.Time, error) {
+	var err error
+	for _, layout := range krakenTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: %w", v, err)
+}
+
+const intelligentDispatchesQuery = `query IntelligentDispatches($accountNumber: String!) {
+  plannedDispatches(accountNumber: $accountNumber) {
+    startDt
+    endDt
+    delta
+    meta {
+      source
+      location
+    }
+  }
+  completedDispatches(accountNumber: $accountNumber) {
+    startDt
+    endDt
+    delta
+    meta {
+      source
+      location
+    }
+  }
+}`
+
+// GetDispatches retrieves the planned and completed smart charge dispatches for an account.
+func (s *IntelligentService) GetDispatches(options *IntelligentDispatchesGetOptions) (*IntelligentDispatchesGetOutput, error) {
+	return s.GetDispatchesWithContext(context.Background(), options)
+}
+
+// GetDispatchesWithContext same as GetDispatches except it takes a Context.
+func (s *IntelligentService) GetDispatchesWithContext(ctx context.Context, options *IntelligentDispatchesGetOptions) (*IntelligentDispatchesGetOutput, error) {
+	res := IntelligentDispatchesGetOutput{}
+	vars := map[string]interface{}{"accountNumber": options.AccountNumber}
+	if err := s.client.GraphQL.QueryWithContext(ctx, intelligentDispatchesQuery, vars, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ApplyDispatches returns a copy of unitRates in which every half hour touched by a dispatch billed
+// off-peak is charged at the given off-peak rate, the way Intelligent Octopus bills smart charging
+// outside the normal off-peak window. Dispatches for which BilledOffPeak is false are ignored. The
+// result can be passed to CostConsumption. Use completed dispatches to reproduce a bill and planned
+// dispatches to forecast one.
+func ApplyDispatches(unitRates []TariffCharge, dispatches []IntelligentDispatch, offPeakExcVat, offPeakIncVat float64) []TariffCharge {
+	windows := dispatchWindows(dispatches)
+	rates := NewChargeIndex(unitRates)
+	if len(windows) == 0 {
+		return rates
+	}
+
+	out := make([]TariffCharge, 0, len(rates)+2*len(windows))
+	for _, r := range rates {
+		cursor := r.ValidFrom
+		for _, w := range windows {
+			if !r.ValidTo.IsZero() && !w.start.Before(r.ValidTo) {
+				break
+			}
+			if !w.end.After(cursor) {
+				continue
+			}
+
+			start := w.start
+			if start.Before(cursor) {
+				start = cursor
+			}
+			end := w.end
+			if !r.ValidTo.IsZero() && end.After(r.ValidTo) {
+				end = r.ValidTo
+			}
+
+			if start.After(cursor) {
+				out = append(out, TariffCharge{ValueExcVat: r.ValueExcVat, ValueIncVat: r.ValueIncVat, ValidFrom: cursor, ValidTo: start})
+			}
+			out = append(out, TariffCharge{ValueExcVat: offPeakExcVat, ValueIncVat: offPeakIncVat, ValidFrom: start, ValidTo: end})
+			cursor = end
+		}
+
+		if r.ValidTo.IsZero() || cursor.Before(r.ValidTo) {
+			out = append(out, TariffCharge{ValueExcVat: r.ValueExcVat, ValueIncVat: r.ValueIncVat, ValidFrom: cursor, ValidTo: r.ValidTo})
+		}
+	}
+
+	return out
+}
+
+type timeWindow struct {
+	start, end time.Time
+}
+
+// dispatchWindows widens dispatches billed off-peak to whole half hours and merges any that overlap.
+func dispatchWindows(dispatches []IntelligentDispatch) []timeWindow {
+	windows := make([]timeWindow, 0, len(dispatches))
+	for _, d := range dispatches {
+		if !d.BilledOffPeak() {
+			continue
+		}
+		start := d.Start.Truncate(30 * time.Minute)
+		end := d.End.Truncate(30 * time.Minute)
+		if end.Before(d.End) {
+			end = end.Add(30 * time.Minute)
+		}
+		if end.After(start) {
+			windows = append(windows, timeWindow{start: start, end: end})
+		}
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].start.Before(windows[j].start)
+	})
+
+	merged := windows[:0]
+	for _, w := range windows {
+		if n := len(merged); n > 0 && !w.start.After(merged[n-1].end) {
+			if w.end.After(merged[n-1].end) {
+				merged[n-1].end = w.end
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
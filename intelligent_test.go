@@ -0,0 +1,70 @@
+package octopusenergy_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+func TestApplyDispatches(t *testing.T) {
+	day := time.Date(2023, 3, 6, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute) }
+
+	// off-peak 23:30 - 05:30, peak otherwise
+	rates := []octopusenergy.TariffCharge{
+		{ValueExcVat: 28, ValueIncVat: 29.4, ValidFrom: at(5, 30), ValidTo: at(23, 30)},
+		{ValueExcVat: 7, ValueIncVat: 7.35, ValidFrom: at(-1, 30), ValidTo: at(5, 30)},
+	}
+
+	var out octopusenergy.IntelligentDispatchesGetOutput
+	err := json.Unmarshal([]byte(`{
+		"plannedDispatches": [],
+		"completedDispatches": [
+			{"startDt": "2023-03-06 13:10:00+00:00", "endDt": "2023-03-06 14:00:00+00:00", "delta": "-5.2", "meta": {"source": "smart-charge", "location": "AT_HOME"}},
+			{"startDt": "2023-03-06 16:00:00+00:00", "endDt": "2023-03-06 17:00:00+00:00", "delta": "-7", "meta": {"source": "bump-charge", "location": "AT_HOME"}},
+			{"startDt": "2023-03-06 18:00:00+00:00", "endDt": "2023-03-06 19:00:00+00:00", "delta": "-7", "meta": {"source": "smart-charge", "location": "AWAY"}}
+		]
+	}`), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := out.Completed[0]; d.Delta != -5.2 || d.Source != "smart-charge" || !d.Start.Equal(at(13, 10)) {
+		t.Fatalf("unexpected dispatch %+v", d)
+	}
+
+	adjusted := octopusenergy.ApplyDispatches(rates, out.Completed, 7, 7.35)
+
+	for _, tc := range []struct {
+		at   time.Time
+		want float64
+	}{
+		{at(2, 0), 7.35},
+		{at(12, 30), 29.4},
+		{at(13, 0), 7.35}, // dispatch starts part way through the half hour
+		{at(13, 30), 7.35},
+		{at(14, 0), 29.4},
+		{at(16, 30), 29.4}, // bump charges are billed at the normal rate
+		{at(18, 30), 29.4}, // as is charging away from home
+	} {
+		rate, ok := octopusenergy.ChargeAt(adjusted, tc.at)
+		if !ok || rate.ValueIncVat != tc.want {
+			t.Errorf("rate at %s: got %v (found %v), want %v", tc.at.Format("15:04"), rate.ValueIncVat, ok, tc.want)
+		}
+	}
+
+	cost := octopusenergy.CostConsumption([]octopusenergy.ConsumptionInterval{
+		{Start: at(13, 0), End: at(13, 30), Consumption: 3},
+		{Start: at(14, 0), End: at(14, 30), Consumption: 1},
+		{Start: at(23, 30), End: at(24, 0), Consumption: 1},
+	}, adjusted)
+
+	if want := 3*7.35 + 29.4; math.Abs(cost.CostIncVat-want) > 1e-9 {
+		t.Errorf("cost: got %v, want %v", cost.CostIncVat, want)
+	}
+	if len(cost.Unpriced) != 1 {
+		t.Errorf("expected the interval without a rate to be unpriced, got %v", cost.Unpriced)
+	}
+}
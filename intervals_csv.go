@@ -0,0 +1,93 @@
+package octopusenergy
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvTimeLayouts are the layouts tried for times in CSV files, those without an offset are read in
+// the UK's local time.
+var csvTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"02/01/2006 15:04:05",
+	"02/01/2006 15:04",
+}
+
+// ReadIntervalsCSV reads half-hourly energy, such as solar generation exported from an inverter or
+// consumption downloaded from the Octopus dashboard, from CSV with a header row. The start time is
+// read from the column named start, time, timestamp or date, an optional end column gives the end
+// time, 30 minutes after the start otherwise, and the energy in kWh from the first other column
+// whose name mentions kwh, consumption, generation or energy. Intervals are ordered by start time.
+func ReadIntervalsCSV(r io.Reader) ([]ConsumptionInterval, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("empty csv")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, energy := -1, -1, -1
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch {
+		case start < 0 && (name == "start" || name == "time" || name == "timestamp" || name == "date" || strings.HasPrefix(name, "start")):
+			start = i
+		case end < 0 && (name == "end" || strings.HasPrefix(name, "end")):
+			end = i
+		case energy < 0 && (strings.Contains(name, "kwh") || strings.Contains(name, "consumption") || strings.Contains(name, "generation") || strings.Contains(name, "energy")):
+			energy = i
+		}
+	}
+	if start < 0 || energy < 0 {
+		return nil, fmt.Errorf("csv needs a start time and kWh column, got %q", header)
+	}
+
+	var intervals []ConsumptionInterval
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var in ConsumptionInterval
+		if in.Start, err = parseCSVTime(record[start]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		in.End = in.Start.Add(30 * time.Minute)
+		if end >= 0 && record[end] != "" {
+			if in.End, err = parseCSVTime(record[end]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if in.Consumption, err = strconv.ParseFloat(strings.TrimSpace(record[energy]), 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid kWh %q", line, record[energy])
+		}
+		intervals = append(intervals, in)
+	}
+	return sortedIntervals(intervals), nil
+}
+
+func parseCSVTime(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.ParseInLocation(layout, v, UKLocation()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", v)
+}
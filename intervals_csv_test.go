@@ -0,0 +1,34 @@
+package octopusenergy_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+func TestReadIntervalsCSV(t *testing.T) {
+	intervals, err := octopusenergy.ReadIntervalsCSV(strings.NewReader("\ufeffConsumption (kWh), Start, End\n" +
+		"0.25,2021-06-01T00:30:00+01:00,2021-06-01T01:00:00+01:00\n" +
+		"0.5,2021-06-01T00:00:00+01:00,2021-06-01T00:30:00+01:00\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 2 || intervals[0].Consumption != 0.5 || !intervals[0].Start.Equal(time.Date(2021, 5, 31, 23, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected intervals %+v", intervals)
+	}
+
+	// inverter exports often have a local timestamp and no end
+	intervals, err = octopusenergy.ReadIntervalsCSV(strings.NewReader("timestamp,generation_kwh\n01/06/2021 12:00,1.2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != 1 || !intervals[0].Start.Equal(time.Date(2021, 6, 1, 11, 0, 0, 0, time.UTC)) || intervals[0].End.Sub(intervals[0].Start) != 30*time.Minute {
+		t.Errorf("unexpected intervals %+v", intervals)
+	}
+
+	if _, err := octopusenergy.ReadIntervalsCSV(strings.NewReader("start,kwh\nyesterday,1\n")); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
@@ -0,0 +1,25 @@
+package octopusenergy
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	ukLocationOnce sync.Once
+	ukLocation     *time.Location
+)
+
+// UKLocation returns the Europe/London time zone, which Octopus uses for days, billing periods and
+// off-peak windows. If the system has no time zone database UTC is returned, import "time/tzdata"
+// in your main package to avoid this.
+func UKLocation() *time.Location {
+	ukLocationOnce.Do(func() {
+		loc, err := time.LoadLocation("Europe/London")
+		if err != nil {
+			loc = time.UTC
+		}
+		ukLocation = loc
+	})
+	return ukLocation
+}
@@ -0,0 +1,158 @@
+//go:generate stringer -linecomment -type=LogLevel
+
+package octopusenergy
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogLevel is the severity of a RequestLog.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota // debug
+	LogLevelInfo                  // info
+	LogLevelWarn                  // warn
+	LogLevelError                 // error
+)
+
+const redacted = "REDACTED"
+
+// RequestLog is the event a Logger receives for a request. A request is logged at LogLevelDebug before
+// it is sent, then at LogLevelInfo once it completes, LogLevelWarn if it was a retry and LogLevelError
+// if it failed.
+type RequestLog struct {
+	Level     LogLevel
+	Message   string
+	Operation Operation
+
+	// Request method and URL, without any credentials.
+	Method string
+	URL    string
+
+	// Request headers, the Authorization header is always redacted. Only set at LogLevelDebug.
+	Header http.Header
+
+	// The page query parameter of a paginated request, 0 if it was not set.
+	Page int
+
+	// Which attempt at the request this is, starting at 1.
+	Attempt int
+
+	// Response status code, 0 if no response was received. Not set at LogLevelDebug.
+	StatusCode int
+
+	// How long the request took. Not set at LogLevelDebug.
+	Duration time.Duration
+
+	// The error the request failed with, if any.
+	Err error
+}
+
+// Logger receives structured events about the requests a client makes. Implementations must be safe
+// for concurrent use.
+type Logger interface {
+	LogRequest(RequestLog)
+}
+
+// LoggerFunc is an adapter to allow the use of an ordinary function as a Logger.
+type LoggerFunc func(RequestLog)
+
+// LogRequest calls f(l).
+func (f LoggerFunc) LogRequest(l RequestLog) {
+	f(l)
+}
+
+// logging is the innermost middleware, so it logs the request exactly as it is sent.
+func (c *Client) logging(next RequestHandler) RequestHandler {
+	return func(op Operation, req *http.Request, out interface{}) (*http.Response, error) {
+		if c.logger == nil {
+			return next(op, req, out)
+		}
+
+		l := RequestLog{
+			Operation: op,
+			Method:    req.Method,
+			URL:       c.redactURL(req.URL),
+			Page:      pageNumber(req.URL),
+			Attempt:   attempt(req.Context()),
+		}
+
+		if c.logLevel <= LogLevelDebug {
+			debug := l
+			debug.Level = LogLevelDebug
+			debug.Message = "sending request"
+			debug.Header = redactHeader(req.Header)
+			c.logger.LogRequest(debug)
+		}
+
+		start := time.Now()
+		res, err := next(op, req, out)
+		l.Duration = time.Since(start)
+		if res != nil {
+			l.StatusCode = res.StatusCode
+		}
+		l.Err = err
+
+		switch {
+		case err != nil:
+			l.Level, l.Message = LogLevelError, "request failed"
+		case l.Attempt > 1:
+			l.Level, l.Message = LogLevelWarn, "request succeeded after retrying"
+		default:
+			l.Level, l.Message = LogLevelInfo, "request completed"
+		}
+		if l.Level >= c.logLevel {
+			c.logger.LogRequest(l)
+		}
+
+		return res, err
+	}
+}
+
+// redactURL returns u as a string without user info, and with the API key removed should it appear.
+func (c *Client) redactURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	s := clean.String()
+	if c.apiKey != "" {
+		s = strings.ReplaceAll(s, url.QueryEscape(c.apiKey), redacted)
+		s = strings.ReplaceAll(s, c.apiKey, redacted)
+	}
+	return s
+}
+
+// redactHeader returns a copy of h with credentials redacted.
+func redactHeader(h http.Header) http.Header {
+	clean := h.Clone()
+	for _, k := range []string{"Authorization", "Proxy-Authorization", "Cookie"} {
+		if clean.Get(k) != "" {
+			clean.Set(k, redacted)
+		}
+	}
+	return clean
+}
+
+func pageNumber(u *url.URL) int {
+	page, _ := strconv.Atoi(u.Query().Get("page"))
+	return page
+}
+
+type attemptKey struct{}
+
+// withAttempt marks ctx as being used for a retry of a request.
+func withAttempt(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, n)
+}
+
+func attempt(ctx context.Context) int {
+	if n, ok := ctx.Value(attemptKey{}).(int); ok {
+		return n
+	}
+	return 1
+}
@@ -0,0 +1,86 @@
+package octopusenergy_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestLogging(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+	srv.SetProductsPageSize(1)
+	for _, code := range []string{"AGILE-18-02-21", "GO-21-05-13"} {
+		srv.AddProduct(octopusenergy.ProductsGetOutput{Code: code, AvailableFrom: time.Now().Add(-time.Hour)})
+	}
+
+	var mu sync.Mutex
+	var logs []octopusenergy.RequestLog
+	logger := octopusenergy.LoggerFunc(func(l octopusenergy.RequestLog) {
+		mu.Lock()
+		defer mu.Unlock()
+		logs = append(logs, l)
+	})
+
+	client := octopusenergy.NewClient(srv.ClientConfig().WithLogger(logger, octopusenergy.LogLevelDebug))
+	if _, err := client.Product.ListPages(&octopusenergy.ProductsListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"}); err == nil {
+		t.Fatal("expected unknown account to fail")
+	}
+
+	var levels []string
+	for _, l := range logs {
+		levels = append(levels, l.Level.String())
+		if s := fmt.Sprintf("%+v", l); strings.Contains(s, octopustest.DefaultAPIKey) || strings.Contains(s, "Basic ") {
+			t.Errorf("credentials leaked into log %s", s)
+		}
+	}
+	if got := strings.Join(levels, ","); got != "debug,info,debug,info,debug,error" {
+		t.Fatalf("unexpected log levels %s", got)
+	}
+
+	if logs[1].Operation.Method != "List" || logs[1].Page != 0 || logs[3].Page != 2 || logs[1].StatusCode != 200 {
+		t.Errorf("unexpected product logs %+v, %+v", logs[1], logs[3])
+	}
+	if logs[4].Header.Get("Authorization") != "REDACTED" {
+		t.Errorf("expected redacted Authorization header, got %q", logs[4].Header.Get("Authorization"))
+	}
+	if logs[5].StatusCode != 404 || logs[5].Err == nil {
+		t.Errorf("expected failed account request, got %+v", logs[5])
+	}
+
+	logs = nil
+	client = octopusenergy.NewClient(srv.ClientConfig().WithLogger(logger, octopusenergy.LogLevelError))
+	if _, err := client.Product.List(&octopusenergy.ProductsListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 0 {
+		t.Errorf("expected only errors to be logged, got %+v", logs)
+	}
+}
+
+func TestApiKeyFromMissingEnvironment(t *testing.T) {
+	if v, ok := os.LookupEnv("OCTOPUS_ENERGY_API_KEY"); ok {
+		defer os.Setenv("OCTOPUS_ENERGY_API_KEY", v)
+		os.Unsetenv("OCTOPUS_ENERGY_API_KEY")
+	}
+
+	cfg := octopusenergy.NewConfig().WithApiKeyFromEnvironments()
+	if cfg.Err() == nil {
+		t.Fatal("expected an error for a missing environment variable")
+	}
+
+	_, err := octopusenergy.NewClient(cfg).GridSupplyPoint.Get(nil)
+	if !errors.Is(err, cfg.Err()) {
+		t.Errorf("expected requests to return the config error, got %v", err)
+	}
+}
@@ -49,7 +49,14 @@ func (s *MeterPointService) GetWithContext(ctx context.Context, options *MeterPo
 	}
 
 	res := MeterPointGetOutput{}
-	if err := s.client.sendRequest(req, true, &res); err != nil {
+	op := Operation{
+		Service:      "MeterPoint",
+		Method:       "Get",
+		HTTPMethod:   http.MethodGet,
+		PathTemplate: "/v1/electricity-meter-points/{mpan}",
+		AuthRequired: true,
+	}
+	if err := s.client.sendRequest(op, req, &res); err != nil {
 		return nil, err
 	}
 
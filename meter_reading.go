@@ -0,0 +1,364 @@
+package octopusenergy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrUnknownMeter is returned when a meter point and serial number is not on the account.
+	ErrUnknownMeter = errors.New("meter not found on account")
+
+	// ErrInvalidMeterReading is wrapped by every error returned from ValidateMeterReading.
+	ErrInvalidMeterReading = errors.New("invalid meter reading")
+)
+
+// MeterReadingService handles communication with the meter reading related parts of the GraphQL API.
+type MeterReadingService service
+
+// MeterReading is a register reading taken from a meter.
+type MeterReading struct {
+	ReadAt time.Time
+
+	// Where the reading came from, example "CUSTOMER" or "SMART".
+	Source string
+
+	Registers []MeterReadingRegister
+}
+
+// MeterReadingRegister is the value of a single register, for example the day or night register
+// of an Economy 7 meter.
+type MeterReadingRegister struct {
+	// The register identifier, as listed in the Registers of a meter on the account.
+	Identifier string
+
+	Value float64
+}
+
+// MeterReadingsListOptions is the options for List.
+type MeterReadingsListOptions struct {
+	// The octopus account number the meter belongs to.
+	AccountNumber string
+
+	// The Meter Point Number this is the electricity meter-point’s MPAN or gas meter-point’s MPRN
+	MPN string
+
+	// The meter’s serial number.
+	SerialNumber string
+
+	// Fueltype: electricity or gas
+	FuelType FuelType
+
+	// Page size of returned results. Default is 20.
+	PageSize *int
+
+	// Pagination cursor to be returned on this request, the Next value of a previous output.
+	Page *string
+}
+
+// MeterReadingsListOutput is the returned struct from List. Next and Previous are page cursors
+// rather than links, they are blank when there is no further page.
+type MeterReadingsListOutput struct {
+	Count    int
+	Next     string
+	Previous string
+	Results  []MeterReading
+}
+
+const meterReadingsQuery = `query MeterReadings($accountNumber: String!, $mpxn: String!, $serialNumber: String!, $first: Int, $after: String) {
+  %sMeterReadings(accountNumber: $accountNumber, mpxn: $mpxn, serialNumber: $serialNumber, first: $first, after: $after) {
+    totalCount
+    pageInfo {
+      hasNextPage
+      endCursor
+      hasPreviousPage
+      startCursor
+    }
+    edges {
+      node {
+        readAt
+        source
+        registers {
+          identifier
+          value
+        }
+      }
+    }
+  }
+}`
+
+// List return the previous readings of a meter, most recent first. This endpoint is paginated, it will
+// return next and previous cursors if there is more data, you are responsible to request the next page
+// if required.
+func (s *MeterReadingService) List(options *MeterReadingsListOptions) (*MeterReadingsListOutput, error) {
+	return s.ListWithContext(context.Background(), options)
+}
+
+// ListWithContext same as List except it takes a Context.
+func (s *MeterReadingService) ListWithContext(ctx context.Context, options *MeterReadingsListOptions) (*MeterReadingsListOutput, error) {
+	var res map[string]struct {
+		graphQLConnection
+		Edges []struct {
+			Node struct {
+				ReadAt    string `json:"readAt"`
+				Source    string `json:"source"`
+				Registers []struct {
+					Identifier string      `json:"identifier"`
+					Value      krakenFloat `json:"value"`
+				} `json:"registers"`
+			} `json:"node"`
+		} `json:"edges"`
+	}
+
+	vars := connectionVariables(options.AccountNumber, options.PageSize, options.Page)
+	vars["mpxn"] = options.MPN
+	vars["serialNumber"] = options.SerialNumber
+
+	query := fmt.Sprintf(meterReadingsQuery, options.FuelType.String())
+	if err := s.client.GraphQL.QueryWithContext(ctx, query, vars, &res); err != nil {
+		return nil, err
+	}
+
+	readings := res[options.FuelType.String()+"MeterReadings"]
+	out := MeterReadingsListOutput{}
+	out.Count, out.Next, out.Previous = readings.page()
+	for _, e := range readings.Edges {
+		readAt, err := parseKrakenTime(e.Node.ReadAt)
+		if err != nil {
+			return nil, err
+		}
+		reading := MeterReading{ReadAt: readAt, Source: e.Node.Source}
+		for _, r := range e.Node.Registers {
+			reading.Registers = append(reading.Registers, MeterReadingRegister{Identifier: r.Identifier, Value: float64(r.Value)})
+		}
+		out.Results = append(out.Results, reading)
+	}
+
+	return &out, nil
+}
+
+// ListPages same as List except it returns all pages in one request.
+func (s *MeterReadingService) ListPages(options *MeterReadingsListOptions) (*MeterReadingsListOutput, error) {
+	return s.ListPagesWithContext(context.Background(), options)
+}
+
+// ListPagesWithContext same as ListPages except it takes a Context.
+func (s *MeterReadingService) ListPagesWithContext(ctx context.Context, options *MeterReadingsListOptions) (*MeterReadingsListOutput, error) {
+	ctx, span := s.client.startPagesSpan(ctx, Operation{Service: "MeterReading", Method: "ListPages", PathTemplate: "/" + graphQLPath, FuelType: options.FuelType.String()})
+	defer span.End()
+
+	options.PageSize = Int(100)
+	options.Page = nil
+
+	fullResp := MeterReadingsListOutput{}
+	var lastPage bool
+
+	for !lastPage {
+		page, err := s.ListWithContext(ctx, options)
+		if err != nil {
+			return nil, spanError(span, err)
+		}
+		fullResp.Count = page.Count
+		fullResp.Results = append(fullResp.Results, page.Results...)
+		if page.Next == "" {
+			lastPage = true
+		}
+		options.Page = String(page.Next)
+	}
+
+	endPagesSpan(span, len(fullResp.Results))
+	return &fullResp, nil
+}
+
+// MeterReadingSubmitOptions is the options for Submit.
+type MeterReadingSubmitOptions struct {
+	// The octopus account number the meter belongs to.
+	AccountNumber string
+
+	// The Meter Point Number this is the electricity meter-point’s MPAN or gas meter-point’s MPRN
+	MPN string
+
+	// The meter’s serial number.
+	SerialNumber string
+
+	// Fueltype: electricity or gas
+	FuelType FuelType
+
+	// When the reading was taken. Defaults to now.
+	ReadAt *time.Time
+
+	// A value for every register on the meter.
+	Registers []MeterReadingRegister
+}
+
+const submitMeterReadingMutation = `mutation SubmitMeterReading($input: %sMeterReadingInput!) {
+  create%sMeterReading(input: $input) {
+    readAt
+  }
+}`
+
+// Submit validates a reading against the meter's registers and its most recent reading, then submits it.
+func (s *MeterReadingService) Submit(options *MeterReadingSubmitOptions) error {
+	return s.SubmitWithContext(context.Background(), options)
+}
+
+// SubmitWithContext same as Submit except it takes a Context.
+func (s *MeterReadingService) SubmitWithContext(ctx context.Context, options *MeterReadingSubmitOptions) error {
+	readAt := time.Now()
+	if options.ReadAt != nil {
+		readAt = *options.ReadAt
+	}
+	reading := MeterReading{ReadAt: readAt, Registers: options.Registers}
+
+	account, err := s.client.Account.GetWithContext(ctx, &AccountGetOptions{AccountNumber: options.AccountNumber})
+	if err != nil {
+		return err
+	}
+	registers, ok := meterRegisters(account, options.FuelType, options.MPN, options.SerialNumber)
+	if !ok {
+		return fmt.Errorf("%w: %s meter %s on %s", ErrUnknownMeter, options.FuelType, options.SerialNumber, options.MPN)
+	}
+
+	previous, err := s.ListWithContext(ctx, &MeterReadingsListOptions{
+		AccountNumber: options.AccountNumber,
+		MPN:           options.MPN,
+		SerialNumber:  options.SerialNumber,
+		FuelType:      options.FuelType,
+		PageSize:      Int(1),
+	})
+	if err != nil {
+		return err
+	}
+	var last *MeterReading
+	if len(previous.Results) > 0 {
+		last = &previous.Results[0]
+	}
+
+	if err := ValidateMeterReading(registers, last, reading); err != nil {
+		return err
+	}
+
+	regs := make([]map[string]interface{}, 0, len(reading.Registers))
+	for _, r := range reading.Registers {
+		regs = append(regs, map[string]interface{}{"identifier": r.Identifier, "value": r.Value})
+	}
+	vars := map[string]interface{}{
+		"input": map[string]interface{}{
+			"accountNumber": options.AccountNumber,
+			"mpxn":          options.MPN,
+			"serialNumber":  options.SerialNumber,
+			"readAt":        readAt.Format(time.RFC3339),
+			"registers":     regs,
+		},
+	}
+
+	fuel := "Electricity"
+	if options.FuelType == FuelTypeGas {
+		fuel = "Gas"
+	}
+	mutation := fmt.Sprintf(submitMeterReadingMutation, fuel, fuel)
+	return s.client.GraphQL.QueryWithContext(ctx, mutation, vars, nil)
+}
+
+// ValidateMeterReading checks a reading before it is submitted. Every one of the meter's register
+// identifiers must be given exactly once, values must not be negative, and neither the time nor any
+// register may go backwards compared to the previous reading, which may be nil. Meters without listed
+// registers, such as gas meters, take a single register with any identifier.
+func ValidateMeterReading(registerIdentifiers []string, previous *MeterReading, reading MeterReading) error {
+	known := map[string]bool{}
+	for _, id := range registerIdentifiers {
+		known[id] = true
+	}
+	if len(known) == 0 && len(reading.Registers) != 1 {
+		return fmt.Errorf("%w: expected a single register, got %d", ErrInvalidMeterReading, len(reading.Registers))
+	}
+	if len(reading.Registers) == 0 {
+		return fmt.Errorf("%w: no registers given", ErrInvalidMeterReading)
+	}
+
+	given := map[string]float64{}
+	for _, r := range reading.Registers {
+		if len(known) > 0 && !known[r.Identifier] {
+			return fmt.Errorf("%w: unknown register %q, expected one of %v", ErrInvalidMeterReading, r.Identifier, registerIdentifiers)
+		}
+		if _, dup := given[r.Identifier]; dup {
+			return fmt.Errorf("%w: register %q given more than once", ErrInvalidMeterReading, r.Identifier)
+		}
+		if r.Value < 0 {
+			return fmt.Errorf("%w: register %q is negative", ErrInvalidMeterReading, r.Identifier)
+		}
+		given[r.Identifier] = r.Value
+	}
+
+	var missing []string
+	for id := range known {
+		if _, ok := given[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: missing registers %v", ErrInvalidMeterReading, missing)
+	}
+
+	if reading.ReadAt.After(time.Now().Add(time.Minute)) {
+		return fmt.Errorf("%w: read at %s is in the future", ErrInvalidMeterReading, reading.ReadAt.Format(time.RFC3339))
+	}
+
+	if previous == nil {
+		return nil
+	}
+
+	if !reading.ReadAt.After(previous.ReadAt) {
+		return fmt.Errorf("%w: read at %s is not after the previous reading at %s",
+			ErrInvalidMeterReading, reading.ReadAt.Format(time.RFC3339), previous.ReadAt.Format(time.RFC3339))
+	}
+	if len(known) == 0 {
+		// the single register may be identified differently from one reading to the next
+		if len(previous.Registers) == 1 && reading.Registers[0].Value < previous.Registers[0].Value {
+			return fmt.Errorf("%w: register went backwards from %v to %v", ErrInvalidMeterReading, previous.Registers[0].Value, reading.Registers[0].Value)
+		}
+		return nil
+	}
+	for _, p := range previous.Registers {
+		if v, ok := given[p.Identifier]; ok && v < p.Value {
+			return fmt.Errorf("%w: register %q went backwards from %v to %v", ErrInvalidMeterReading, p.Identifier, p.Value, v)
+		}
+	}
+
+	return nil
+}
+
+// meterRegisters finds the register identifiers of a meter on the account.
+func meterRegisters(account *AccountGetOutput, fuelType FuelType, mpn, serialNumber string) ([]string, bool) {
+	for _, property := range account.Properties {
+		var meters []Meter
+		if fuelType == FuelTypeElectricity {
+			for _, mp := range property.ElectricityMeterPoints {
+				if mp.MPAN == mpn {
+					meters = append(meters, mp.Meters...)
+				}
+			}
+		} else {
+			for _, mp := range property.GasMeterPoints {
+				if mp.MPRN == mpn {
+					meters = append(meters, mp.Meters...)
+				}
+			}
+		}
+
+		for _, m := range meters {
+			if m.SerialNumber == serialNumber {
+				ids := make([]string, 0, len(m.Registers))
+				for _, r := range m.Registers {
+					ids = append(ids, r.Identifier)
+				}
+				return ids, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,203 @@
+package octopusenergy_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestValidateMeterReading(t *testing.T) {
+	readAt := time.Now().Add(-time.Hour)
+	previous := &octopusenergy.MeterReading{
+		ReadAt:    readAt.AddDate(0, 0, -30),
+		Registers: []octopusenergy.MeterReadingRegister{{Identifier: "1", Value: 1000}, {Identifier: "2", Value: 500}},
+	}
+	economy7 := []string{"1", "2"}
+	registers := func(values ...float64) []octopusenergy.MeterReadingRegister {
+		var out []octopusenergy.MeterReadingRegister
+		for i, v := range values {
+			out = append(out, octopusenergy.MeterReadingRegister{Identifier: string(rune('1' + i)), Value: v})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		registers []string
+		previous  *octopusenergy.MeterReading
+		reading   octopusenergy.MeterReading
+		err       string
+	}{
+		{
+			name:      "valid",
+			registers: economy7,
+			previous:  previous,
+			reading:   octopusenergy.MeterReading{ReadAt: readAt, Registers: registers(1100, 550)},
+		},
+		{
+			name:      "first reading",
+			registers: economy7,
+			reading:   octopusenergy.MeterReading{ReadAt: readAt, Registers: registers(0, 0)},
+		},
+		{
+			name:      "unknown register",
+			registers: economy7,
+			reading:   octopusenergy.MeterReading{ReadAt: readAt, Registers: append(registers(1100, 550), octopusenergy.MeterReadingRegister{Identifier: "3"})},
+			err:       `unknown register "3"`,
+		},
+		{
+			name:      "missing register",
+			registers: economy7,
+			reading:   octopusenergy.MeterReading{ReadAt: readAt, Registers: registers(1100)},
+			err:       "missing registers [2]",
+		},
+		{
+			name:      "duplicate register",
+			registers: economy7,
+			reading:   octopusenergy.MeterReading{ReadAt: readAt, Registers: append(registers(1100, 550), octopusenergy.MeterReadingRegister{Identifier: "1", Value: 1100})},
+			err:       `register "1" given more than once`,
+		},
+		{
+			name:      "negative register",
+			registers: economy7,
+			reading:   octopusenergy.MeterReading{ReadAt: readAt, Registers: registers(1100, -1)},
+			err:       `register "2" is negative`,
+		},
+		{
+			name:      "no registers",
+			registers: economy7,
+			reading:   octopusenergy.MeterReading{ReadAt: readAt},
+			err:       "no registers given",
+		},
+		{
+			name:      "future read time",
+			registers: economy7,
+			reading:   octopusenergy.MeterReading{ReadAt: time.Now().Add(time.Hour), Registers: registers(1100, 550)},
+			err:       "is in the future",
+		},
+		{
+			name:      "read time not after previous",
+			registers: economy7,
+			previous:  previous,
+			reading:   octopusenergy.MeterReading{ReadAt: previous.ReadAt, Registers: registers(1100, 550)},
+			err:       "is not after the previous reading",
+		},
+		{
+			name:      "register backwards",
+			registers: economy7,
+			previous:  previous,
+			reading:   octopusenergy.MeterReading{ReadAt: readAt, Registers: registers(1100, 499)},
+			err:       `register "2" went backwards from 500 to 499`,
+		},
+		{
+			name:     "single register",
+			previous: &octopusenergy.MeterReading{ReadAt: previous.ReadAt, Registers: []octopusenergy.MeterReadingRegister{{Identifier: "gas", Value: 2000}}},
+			reading:  octopusenergy.MeterReading{ReadAt: readAt, Registers: registers(2001)},
+		},
+		{
+			name:     "single register backwards",
+			previous: &octopusenergy.MeterReading{ReadAt: previous.ReadAt, Registers: []octopusenergy.MeterReadingRegister{{Identifier: "gas", Value: 2000}}},
+			reading:  octopusenergy.MeterReading{ReadAt: readAt, Registers: registers(1999)},
+			err:      "register went backwards from 2000 to 1999",
+		},
+		{
+			name:    "single register given twice",
+			reading: octopusenergy.MeterReading{ReadAt: readAt, Registers: registers(1, 2)},
+			err:     "expected a single register, got 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := octopusenergy.ValidateMeterReading(tt.registers, tt.previous, tt.reading)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, octopusenergy.ErrInvalidMeterReading) || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected an invalid reading error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestMeterReadingSubmit(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	srv.AddAccount(octopusenergy.AccountGetOutput{
+		Number: "A-AAAA1111",
+		Properties: []octopusenergy.Property{{
+			GasMeterPoints: []octopusenergy.GasMeterPoints{{
+				MPRN:   "2222222222",
+				Meters: []octopusenergy.Meter{{SerialNumber: "G4A0000000"}},
+			}},
+		}},
+	})
+
+	var submitted []map[string]interface{}
+	srv.GraphQL = krakenGraphQL(func(query string, vars map[string]interface{}) string {
+		if strings.Contains(query, "createGasMeterReading") {
+			submitted = append(submitted, vars["input"].(map[string]interface{}))
+			return `{"data":{"createGasMeterReading":{"readAt":"2021-03-01T09:00:00+00:00"}}}`
+		}
+		if vars["mpxn"] != "2222222222" || vars["serialNumber"] != "G4A0000000" || vars["first"] != float64(1) {
+			return `{"errors":[{"message":"unexpected variables"}]}`
+		}
+		return `{"data":{"gasMeterReadings":{"totalCount":1,"pageInfo":{"hasNextPage":true,"endCursor":"YXJyYXljb25uZWN0aW9uOjA="},
+			"edges":[{"node":{"readAt":"2021-02-01T09:00:00+00:00","source":"CUSTOMER","registers":[{"identifier":"1","value":"2000.5"}]}}]}}}`
+	})
+	client := srv.Client()
+
+	previous, err := client.MeterReading.List(&octopusenergy.MeterReadingsListOptions{
+		AccountNumber: "A-AAAA1111",
+		MPN:           "2222222222",
+		SerialNumber:  "G4A0000000",
+		FuelType:      octopusenergy.FuelTypeGas,
+		PageSize:      octopusenergy.Int(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous.Count != 1 || previous.Next == "" || len(previous.Results) != 1 || previous.Results[0].Registers[0].Value != 2000.5 {
+		t.Errorf("unexpected readings %+v", previous)
+	}
+
+	readAt := time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC)
+	options := octopusenergy.MeterReadingSubmitOptions{
+		AccountNumber: "A-AAAA1111",
+		MPN:           "2222222222",
+		SerialNumber:  "G4A0000000",
+		FuelType:      octopusenergy.FuelTypeGas,
+		ReadAt:        &readAt,
+		Registers:     []octopusenergy.MeterReadingRegister{{Identifier: "1", Value: 2100}},
+	}
+	if err := client.MeterReading.Submit(&options); err != nil {
+		t.Fatal(err)
+	}
+	if len(submitted) != 1 || submitted[0]["readAt"] != "2021-03-01T09:00:00Z" || submitted[0]["mpxn"] != "2222222222" {
+		t.Fatalf("unexpected submission %v", submitted)
+	}
+	if regs := submitted[0]["registers"].([]interface{}); len(regs) != 1 || regs[0].(map[string]interface{})["value"] != float64(2100) {
+		t.Errorf("unexpected registers %v", regs)
+	}
+
+	// a reading lower than the last is not sent
+	options.Registers[0].Value = 1999
+	if err := client.MeterReading.Submit(&options); !errors.Is(err, octopusenergy.ErrInvalidMeterReading) {
+		t.Errorf("expected an invalid reading, got %v", err)
+	}
+	options.SerialNumber = "G4A9999999"
+	if err := client.MeterReading.Submit(&options); !errors.Is(err, octopusenergy.ErrUnknownMeter) {
+		t.Errorf("expected an unknown meter, got %v", err)
+	}
+	if len(submitted) != 1 {
+		t.Errorf("expected invalid readings not to be submitted, got %d submissions", len(submitted))
+	}
+}
@@ -0,0 +1,177 @@
+package octopusenergy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Operation describes the API call a request is being made for, so middleware can tell which
+// service and method it is wrapping without parsing URLs.
+type Operation struct {
+	// The service the call was made on, example "Consumption" or "GraphQL".
+	Service string
+
+	// The method called, example "Get". For GraphQL requests this is the operation name of the query
+	// or mutation, example "ObtainKrakenToken".
+	Method string
+
+	// The HTTP method of the request.
+	HTTPMethod string
+
+	// The request path with its parameters left as placeholders, example "/v1/accounts/{account_number}/".
+	PathTemplate string
+
+	// Whether the endpoint requires the caller to be authenticated.
+	AuthRequired bool
+
+	// The fuel type of the meter point or tariff, blank when the call is not fuel specific.
+	FuelType string
+
+	// Whether sending the request again has no further effect, so it is safe to retry. Set for GraphQL
+	// queries, which are sent as POST requests, but not mutations. GET requests are always idempotent.
+	Idempotent bool
+}
+
+// RequestHandler sends a request for an operation and decodes the response body into out. The returned
+// response is nil if no response was received, its body has already been read and closed.
+type RequestHandler func(op Operation, req *http.Request, out interface{}) (*http.Response, error)
+
+// Middleware wraps a RequestHandler, it can change the request before calling next, inspect the
+// response and decoded output after, or not call next at all and fill in out itself.
+//
+//	audit := func(next octopusenergy.RequestHandler) octopusenergy.RequestHandler {
+//		return func(op octopusenergy.Operation, req *http.Request, out interface{}) (*http.Response, error) {
+//			res, err := next(op, req, out)
+//			log.Printf("%s.%s: %v", op.Service, op.Method, err)
+//			return res, err
+//		}
+//	}
+type Middleware func(next RequestHandler) RequestHandler
+
+// chain wraps h in middleware, the first middleware being the outermost.
+func chain(h RequestHandler, middleware []Middleware) RequestHandler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+var graphQLOperationName = regexp.MustCompile(`^\s*(query|mutation|subscription)\s+(\w+)`)
+
+// graphQLOperation returns the Operation for a GraphQL query, named after the query's operation name.
+func graphQLOperation(query string, authed bool) Operation {
+	op := Operation{
+		Service:      "GraphQL",
+		Method:       "Query",
+		HTTPMethod:   http.MethodPost,
+		PathTemplate: "/" + graphQLPath,
+		AuthRequired: authed,
+		Idempotent:   true,
+	}
+	if m := graphQLOperationName.FindStringSubmatch(query); m != nil {
+		op.Method = m[2]
+		op.Idempotent = m[1] != "mutation"
+	}
+	return op
+}
+
+// Retry returns middleware that retries requests failing with 429 Too Many Requests, and idempotent
+// requests failing with a network error or a 5xx status, up to maxAttempts attempts in total. Others,
+// such as GraphQL mutations, may have taken effect before failing so are not retried. It waits backoff
+// before the first retry, doubling for each one after.
+func Retry(maxAttempts int, backoff time.Duration) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(op Operation, req *http.Request, out interface{}) (*http.Response, error) {
+			ctx := req.Context()
+			wait := backoff
+			for n := 1; ; n++ {
+				attemptReq := req
+				if n > 1 {
+					attemptReq = req.Clone(withAttempt(ctx, n))
+					if req.GetBody != nil {
+						body, err := req.GetBody()
+						if err != nil {
+							return nil, err
+						}
+						attemptReq.Body = body
+					}
+				}
+
+				res, err := next(op, attemptReq, out)
+				if err == nil || n >= maxAttempts || !retryable(ctx, op, req, res, err) {
+					return res, err
+				}
+
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return res, err
+				}
+				wait *= 2
+			}
+		}
+	}
+}
+
+func retryable(ctx context.Context, op Operation, req *http.Request, res *http.Response, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	// rate limited requests were not processed
+	if res != nil && res.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	if req.Method != http.MethodGet && !op.Idempotent {
+		return false
+	}
+	return res == nil || res.StatusCode >= http.StatusInternalServerError
+}
+
+// Cache returns middleware that keeps successful responses to GET requests in memory for ttl, and answers
+// repeated requests for the same URL from it. Each client should be given its own Cache.
+func Cache(ttl time.Duration) Middleware {
+	type entry struct {
+		body    []byte
+		expires time.Time
+	}
+	var mu sync.Mutex
+	entries := map[string]entry{}
+
+	return func(next RequestHandler) RequestHandler {
+		return func(op Operation, req *http.Request, out interface{}) (*http.Response, error) {
+			if req.Method != http.MethodGet {
+				return next(op, req, out)
+			}
+
+			key := req.URL.String()
+			now := time.Now()
+
+			mu.Lock()
+			e, ok := entries[key]
+			if ok && !now.Before(e.expires) {
+				delete(entries, key)
+				ok = false
+			}
+			mu.Unlock()
+			if ok {
+				return nil, json.Unmarshal(e.body, out)
+			}
+
+			res, err := next(op, req, out)
+			if err != nil {
+				return res, err
+			}
+			if body, err := json.Marshal(out); err == nil {
+				mu.Lock()
+				entries[key] = entry{body: body, expires: now.Add(ttl)}
+				mu.Unlock()
+			}
+			return res, nil
+		}
+	}
+}
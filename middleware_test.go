@@ -0,0 +1,121 @@
+package octopusenergy_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestMiddleware(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+	srv.AddAccount(octopusenergy.AccountGetOutput{Number: "A-AAAA1111"})
+	srv.AddGridSupplyPoint("SW1A 1AA", "_C")
+
+	var calls []string
+	var ops []octopusenergy.Operation
+	record := func(name string) octopusenergy.Middleware {
+		return func(next octopusenergy.RequestHandler) octopusenergy.RequestHandler {
+			return func(op octopusenergy.Operation, req *http.Request, out interface{}) (*http.Response, error) {
+				calls = append(calls, name)
+				if name == "outer" {
+					ops = append(ops, op)
+				}
+				req.Header.Set("X-Request-Id", "abc")
+				return next(op, req, out)
+			}
+		}
+	}
+
+	// short circuits grid supply point lookups, without reaching the server
+	cached := func(next octopusenergy.RequestHandler) octopusenergy.RequestHandler {
+		return func(op octopusenergy.Operation, req *http.Request, out interface{}) (*http.Response, error) {
+			if op.Service == "GridSupplyPoint" {
+				res := out.(*octopusenergy.GridSupplyPointGetOutput)
+				res.Count = 1
+				res.Results = append(res.Results, struct {
+					GroupID string `json:"group_id"`
+				}{GroupID: "_Z"})
+				return nil, nil
+			}
+			return next(op, req, out)
+		}
+	}
+
+	client := octopusenergy.NewClient(srv.ClientConfig().WithMiddleware(record("outer"), record("inner")))
+	client.Use(cached)
+
+	account, err := client.Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"})
+	if err != nil || account.Number != "A-AAAA1111" {
+		t.Fatalf("unexpected account %v, %v", account, err)
+	}
+	if len(calls) != 2 || calls[0] != "outer" || calls[1] != "inner" {
+		t.Errorf("expected middleware in order, got %v", calls)
+	}
+	want := octopusenergy.Operation{
+		Service:      "Account",
+		Method:       "Get",
+		HTTPMethod:   http.MethodGet,
+		PathTemplate: "/v1/accounts/{account_number}/",
+		AuthRequired: true,
+	}
+	if ops[0] != want {
+		t.Errorf("expected operation %+v, got %+v", want, ops[0])
+	}
+	if got := srv.Requests()[0].Header.Get("X-Request-Id"); got != "abc" {
+		t.Errorf("expected injected header, got %q", got)
+	}
+
+	gsp, err := client.GridSupplyPoint.Get(nil)
+	if err != nil || len(gsp.Results) != 1 || gsp.Results[0].GroupID != "_Z" {
+		t.Errorf("expected cached grid supply point, got %v, %v", gsp, err)
+	}
+	if len(srv.Requests()) != 1 {
+		t.Errorf("expected cached request not to reach the server, got %d requests", len(srv.Requests()))
+	}
+}
+
+func TestRetry(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+	srv.AddAccount(octopusenergy.AccountGetOutput{Number: "A-AAAA1111"})
+
+	attempts := map[string]int{}
+	kraken := krakenGraphQL(func(query string, vars map[string]interface{}) string {
+		return `{"data":{}}`
+	})
+	srv.GraphQL = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		for _, name := range []string{"Balance", "SubmitMeterReading"} {
+			if strings.Contains(string(body), name) {
+				// every first attempt fails after the server has done the work
+				if attempts[name]++; attempts[name] == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+			}
+		}
+		kraken.ServeHTTP(w, r)
+	})
+	srv.InjectFault(octopustest.Fault{Path: "accounts", StatusCode: http.StatusServiceUnavailable, Times: 1})
+
+	client := octopusenergy.NewClient(srv.ClientConfig().WithMiddleware(octopusenergy.Retry(3, time.Millisecond)))
+
+	if _, err := client.Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"}); err != nil {
+		t.Errorf("expected a GET to be retried, got %v", err)
+	}
+	if err := client.GraphQL.Query(`query Balance { viewer { preferredName } }`, nil, nil); err != nil || attempts["Balance"] != 2 {
+		t.Errorf("expected a GraphQL query to be retried, got %v after %d attempts", err, attempts["Balance"])
+	}
+	err := client.GraphQL.Query(`mutation SubmitMeterReading($input: GasMeterReadingInput!) { createGasMeterReading(input: $input) { readAt } }`, nil, nil)
+	if err == nil || attempts["SubmitMeterReading"] != 1 {
+		t.Errorf("expected a failing mutation not to be retried, got %v after %d attempts", err, attempts["SubmitMeterReading"])
+	}
+}
@@ -1,18 +1,18 @@
 //go:generate stringer -linecomment -type=FuelType
 
-// Package octopusenergy proves an interface for Octopus Energy REST APIs.
+// Package octopusenergy proves an interface for Octopus Energy REST and GraphQL APIs.
 package octopusenergy
 
 import (
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"reflect"
 
 	"github.com/google/go-querystring/query"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -42,12 +42,31 @@ type Client struct {
 	//
 	auth string
 
+	// The raw API key, exchanged for a Kraken token when using the GraphQL API.
+	apiKey string
+
+	// Cached Kraken token for the GraphQL API.
+	krakenToken krakenToken
+
 	// User agent used when communicating with the GitHub API.
 	userAgent string
 
 	// HTTP client used to communicate with the API.
 	HTTPClient *http.Client
 
+	// Middleware wrapped around every request, the first being the outermost.
+	middleware []Middleware
+
+	// Receives events about requests, nil disables logging.
+	logger   Logger
+	logLevel LogLevel
+
+	// Creates the spans traced for every request, nil uses the global TracerProvider.
+	tracerProvider trace.TracerProvider
+
+	// Returned by every request when the client's config was invalid.
+	err error
+
 	common service // Reuse a single struct instead of allocating one for each service on the heap.
 
 	// Services used for talking to different parts of the Octopus API.
@@ -57,16 +76,30 @@ type Client struct {
 	GridSupplyPoint *GridSupplyPointService
 	Consumption     *ConsumptionService
 	Account         *AccountService
+	GraphQL         *GraphQLService
+	Intelligent     *IntelligentService
+	Telemetry       *TelemetryService
+	SavingSession   *SavingSessionService
+	MeterReading    *MeterReadingService
+	Household       *HouseholdService
 }
 
-// NewClient accepts a config object and returns an initiated client ready to use.
+// NewClient accepts a config object and returns an initiated client ready to use. If the config is
+// invalid, for example its Endpoint is not an absolute URL, Err returns why and so does every request.
 func NewClient(cfg *Config) *Client {
-	url, _ := url.Parse(defaultBaseURL)
+	baseURL, _ := url.Parse(defaultBaseURL)
 	httpClient := http.DefaultClient
-	var auth string
+	var auth, apiKey string
+	cfgErr := cfg.err
 
 	if cfg.Endpoint != nil {
-		url, _ = url.Parse(*cfg.Endpoint)
+		endpoint, err := parseEndpoint(*cfg.Endpoint)
+		if err != nil && cfgErr == nil {
+			cfgErr = err
+		}
+		if err == nil {
+			baseURL = endpoint
+		}
 	}
 
 	if cfg.HTTPClient != nil {
@@ -74,14 +107,22 @@ func NewClient(cfg *Config) *Client {
 	}
 
 	if cfg.ApiKey != nil {
-		auth = base64.StdEncoding.EncodeToString([]byte(*cfg.ApiKey + ":"))
+		apiKey = *cfg.ApiKey
+		auth = base64.StdEncoding.EncodeToString([]byte(apiKey + ":"))
 	}
 
 	c := &Client{
-		BaseURL:    *url,
+		BaseURL:    *baseURL,
 		auth:       auth,
+		apiKey:     apiKey,
 		userAgent:  userAgent,
 		HTTPClient: httpClient,
+		middleware: append([]Middleware(nil), cfg.Middleware...),
+		logger:     cfg.Logger,
+		logLevel:   cfg.LogLevel,
+		err:        cfgErr,
+
+		tracerProvider: cfg.TracerProvider,
 	}
 
 	c.common.client = c
@@ -91,10 +132,28 @@ func NewClient(cfg *Config) *Client {
 	c.GridSupplyPoint = (*GridSupplyPointService)(&c.common)
 	c.Consumption = (*ConsumptionService)(&c.common)
 	c.Account = (*AccountService)(&c.common)
+	c.GraphQL = (*GraphQLService)(&c.common)
+	c.Intelligent = (*IntelligentService)(&c.common)
+	c.Telemetry = (*TelemetryService)(&c.common)
+	c.SavingSession = (*SavingSessionService)(&c.common)
+	c.MeterReading = (*MeterReadingService)(&c.common)
+	c.Household = (*HouseholdService)(&c.common)
 
 	return c
 }
 
+// parseEndpoint parses an API base URL, which must be absolute.
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint %q: must be an absolute http or https URL", endpoint)
+	}
+	return u, nil
+}
+
 func addParameters(url *url.URL, parameters interface{}) (*url.URL, error) {
 	v := reflect.ValueOf(parameters)
 	if v.Kind() == reflect.Ptr && v.IsNil() {
@@ -109,34 +168,52 @@ func addParameters(url *url.URL, parameters interface{}) (*url.URL, error) {
 	return url, nil
 }
 
-func (c *Client) sendRequest(req *http.Request, authed bool, castTo interface{}) error {
+// Err returns the error that makes the client's config invalid, or nil if it is valid.
+func (c *Client) Err() error {
+	return c.err
+}
+
+// Use adds middleware around every request the client makes, after any already added.
+// It must not be called while requests are in flight.
+func (c *Client) Use(middleware ...Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+func (c *Client) sendRequest(op Operation, req *http.Request, castTo interface{}) error {
+	if c.err != nil {
+		return c.err
+	}
+
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("Accept", "application/json; charset=utf-8")
 	req.Header.Set("User-Agent", c.userAgent)
 
-	if authed && c.auth != "" {
+	if op.AuthRequired && c.auth != "" && req.Header.Get("Authorization") == "" {
 		req.Header.Set("Authorization", "Basic "+c.auth)
 	}
 
+	_, err := chain(c.tracing(c.logging(c.do)), c.middleware)(op, req, castTo)
+	return err
+}
+
+// do is the innermost RequestHandler, it sends the request and decodes the response.
+func (c *Client) do(op Operation, req *http.Request, castTo interface{}) (*http.Response, error) {
 	res, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer res.Body.Close()
 
 	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
 		var errRes errorResponse
-		if err = json.NewDecoder(res.Body).Decode(&errRes); err == nil {
-			// TODO: return common custom errors types
-			return errors.New(errRes.Detail)
-		}
-		return fmt.Errorf("unknown error, status code: %d", res.StatusCode)
+		_ = json.NewDecoder(res.Body).Decode(&errRes)
+		return res, &APIError{StatusCode: res.StatusCode, Detail: errRes.Detail}
 	}
 
 	if err = json.NewDecoder(res.Body).Decode(&castTo); err != nil {
-		return err
+		return res, err
 	}
 
-	return nil
+	return res, nil
 }
@@ -0,0 +1,473 @@
+package octopustest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+const (
+	maxTariffChargesPageSize = 1500
+	maxConsumptionPageSize   = 25000
+)
+
+// AddProduct adds, or replaces, a product. Products are listed in the order they were first added.
+func (s *Server) AddProduct(product octopusenergy.ProductsGetOutput) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.products[product.Code]; !ok {
+		s.productOrder = append(s.productOrder, product.Code)
+	}
+	s.products[product.Code] = product
+}
+
+// AddTariffCharges adds charges to a tariff's rate, example the standard unit rates of an Agile tariff.
+func (s *Server) AddTariffCharges(productCode string, fuelType octopusenergy.FuelType, tariffCode string, rate octopusenergy.Rate, charges ...octopusenergy.TariffCharge) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := tariffKey(productCode, fuelType.String(), tariffCode, rate.String())
+	s.tariffCharges[key] = append(s.tariffCharges[key], charges...)
+}
+
+// AddConsumption adds half hourly consumption to a meter.
+func (s *Server) AddConsumption(fuelType octopusenergy.FuelType, mpn, serialNumber string, intervals ...octopusenergy.ConsumptionInterval) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := consumptionKey(fuelType.String(), mpn, serialNumber)
+	s.consumption[key] = append(s.consumption[key], intervals...)
+}
+
+// AddGridSupplyPoint maps a postcode to a GSP group ID, example "_H".
+func (s *Server) AddGridSupplyPoint(postcode, groupID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gridSupplyPoints[normalisePostcode(postcode)] = groupID
+}
+
+// AddMeterPoint adds, or replaces, an electricity meter point.
+func (s *Server) AddMeterPoint(meterPoint octopusenergy.MeterPointGetOutput) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meterPoints[meterPoint.MPAN] = meterPoint
+}
+
+// AddAccount adds, or replaces, an account.
+func (s *Server) AddAccount(account octopusenergy.AccountGetOutput) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accounts[account.Number] = account
+}
+
+func tariffKey(productCode, fuelType, tariffCode, rate string) string {
+	return strings.Join([]string{productCode, fuelType, tariffCode, rate}, "|")
+}
+
+func consumptionKey(fuelType, mpn, serialNumber string) string {
+	return strings.Join([]string{fuelType, mpn, serialNumber}, "|")
+}
+
+func normalisePostcode(postcode string) string {
+	return strings.ToUpper(strings.ReplaceAll(postcode, " ", ""))
+}
+
+type productSummary struct {
+	Code          string      `json:"code"`
+	FullName      string      `json:"full_name"`
+	DisplayName   string      `json:"display_name"`
+	Description   string      `json:"description"`
+	IsVariable    bool        `json:"is_variable"`
+	IsGreen       bool        `json:"is_green"`
+	IsTracker     bool        `json:"is_tracker"`
+	IsPrepay      bool        `json:"is_prepay"`
+	IsBusiness    bool        `json:"is_business"`
+	IsRestricted  bool        `json:"is_restricted"`
+	Term          int         `json:"term"`
+	Brand         string      `json:"brand"`
+	AvailableFrom time.Time   `json:"available_from"`
+	AvailableTo   *time.Time  `json:"available_to"`
+	Links         interface{} `json:"links"`
+}
+
+func (s *Server) listProducts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	size := s.productsPageSize
+	var matched []productSummary
+	for _, code := range s.productOrder {
+		p := s.products[code]
+		if !boolFilter(q.Get("is_variable"), p.IsVariable) ||
+			!boolFilter(q.Get("is_green"), p.IsGreen) ||
+			!boolFilter(q.Get("is_tracker"), p.IsTracker) ||
+			!boolFilter(q.Get("is_prepay"), p.IsPrepay) ||
+			!boolFilter(q.Get("is_business"), p.IsBusiness) {
+			continue
+		}
+		if v := q.Get("available_at"); v != "" {
+			at, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				s.mu.Unlock()
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid available_at %q", v))
+				return
+			}
+			if at.Before(p.AvailableFrom) || (!p.AvailableTo.IsZero() && !at.Before(p.AvailableTo)) {
+				continue
+			}
+		}
+
+		summary := productSummary{
+			Code:          p.Code,
+			FullName:      p.FullName,
+			DisplayName:   p.DisplayName,
+			Description:   p.Description,
+			IsVariable:    p.IsVariable,
+			IsGreen:       p.IsGreen,
+			IsTracker:     p.IsTracker,
+			IsPrepay:      p.IsPrepay,
+			IsBusiness:    p.IsBusiness,
+			IsRestricted:  p.IsRestricted,
+			Term:          p.Term,
+			Brand:         p.Brand,
+			AvailableFrom: p.AvailableFrom,
+			Links:         p.Links,
+		}
+		if !p.AvailableTo.IsZero() {
+			summary.AvailableTo = &p.AvailableTo
+		}
+		matched = append(matched, summary)
+	}
+	s.mu.Unlock()
+
+	// the products endpoint ignores page_size, fix it to the server setting
+	q.Del("page_size")
+	r.URL.RawQuery = q.Encode()
+
+	start, end, p, err := paginate(r, len(matched), size, size)
+	if err != nil {
+		writePageError(w, err)
+		return
+	}
+	p.Results = nonNil(matched[start:end])
+	writeJSON(w, p)
+}
+
+func (s *Server) getProduct(w http.ResponseWriter, r *http.Request, code string) {
+	s.mu.Lock()
+	product, ok := s.products[code]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	product.TariffsActiveAt = time.Now().UTC()
+	if v := r.URL.Query().Get("tariffs_active_at"); v != "" {
+		at, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid tariffs_active_at %q", v))
+			return
+		}
+		product.TariffsActiveAt = at
+	}
+	writeJSON(w, product)
+}
+
+type tariffChargeJSON struct {
+	ValueExcVat float64    `json:"value_exc_vat"`
+	ValueIncVat float64    `json:"value_inc_vat"`
+	ValidFrom   time.Time  `json:"valid_from"`
+	ValidTo     *time.Time `json:"valid_to"`
+}
+
+func (s *Server) getTariffCharges(w http.ResponseWriter, r *http.Request, productCode, fuelType, tariffCode, rate string) {
+	from, to, err := periodFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	_, productExists := s.products[productCode]
+	charges, ok := s.tariffCharges[tariffKey(productCode, fuelType, tariffCode, rate)]
+	s.mu.Unlock()
+
+	if !ok && !productExists {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	var matched []tariffChargeJSON
+	for _, c := range charges {
+		// a charge is included if it is active at any point in the requested period
+		if to != nil && !c.ValidFrom.Before(*to) {
+			continue
+		}
+		if from != nil && !c.ValidTo.IsZero() && !c.ValidTo.After(*from) {
+			continue
+		}
+		out := tariffChargeJSON{ValueExcVat: c.ValueExcVat, ValueIncVat: c.ValueIncVat, ValidFrom: c.ValidFrom}
+		if !c.ValidTo.IsZero() {
+			validTo := c.ValidTo
+			out.ValidTo = &validTo
+		}
+		matched = append(matched, out)
+	}
+
+	// most recent first, like the real API
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].ValidFrom.After(matched[j].ValidFrom)
+	})
+
+	start, end, p, err := paginate(r, len(matched), defaultPageSize, maxTariffChargesPageSize)
+	if err != nil {
+		writePageError(w, err)
+		return
+	}
+	p.Results = nonNil(matched[start:end])
+	writeJSON(w, p)
+}
+
+type consumptionJSON struct {
+	Consumption   float64 `json:"consumption"`
+	IntervalStart string  `json:"interval_start"`
+	IntervalEnd   string  `json:"interval_end"`
+}
+
+func (s *Server) getConsumption(w http.ResponseWriter, r *http.Request, fuelType, mpn, serialNumber string) {
+	q := r.URL.Query()
+
+	from, to, err := periodFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	intervals, ok := s.consumption[consumptionKey(fuelType, mpn, serialNumber)]
+	intervals = append([]octopusenergy.ConsumptionInterval(nil), intervals...)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	var matched []octopusenergy.ConsumptionInterval
+	for _, in := range intervals {
+		if from != nil && in.Start.Before(*from) {
+			continue
+		}
+		if to != nil && !in.Start.Before(*to) {
+			continue
+		}
+		matched = append(matched, in)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Start.Before(matched[j].Start)
+	})
+
+	if groupBy := q.Get("group_by"); groupBy != "" {
+		if matched, err = group(matched, groupBy); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	switch q.Get("order_by") {
+	case "", "-period":
+		for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+			matched[i], matched[j] = matched[j], matched[i]
+		}
+	case "period":
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid order_by %q", q.Get("order_by")))
+		return
+	}
+
+	start, end, p, err := paginate(r, len(matched), defaultPageSize, maxConsumptionPageSize)
+	if err != nil {
+		writePageError(w, err)
+		return
+	}
+
+	loc := octopusenergy.UKLocation()
+	results := make([]consumptionJSON, 0, end-start)
+	for _, in := range matched[start:end] {
+		results = append(results, consumptionJSON{
+			Consumption:   in.Consumption,
+			IntervalStart: in.Start.In(loc).Format(time.RFC3339),
+			IntervalEnd:   in.End.In(loc).Format(time.RFC3339),
+		})
+	}
+	p.Results = results
+	writeJSON(w, p)
+}
+
+// group aggregates ordered intervals into periods starting at midnight UK time, as the real API does.
+func group(intervals []octopusenergy.ConsumptionInterval, groupBy string) ([]octopusenergy.ConsumptionInterval, error) {
+	loc := octopusenergy.UKLocation()
+
+	var bucket func(t time.Time) (time.Time, time.Time)
+	switch groupBy {
+	case "hour":
+		bucket = func(t time.Time) (time.Time, time.Time) {
+			start := t.Truncate(time.Hour)
+			return start, start.Add(time.Hour)
+		}
+	case "day":
+		bucket = func(t time.Time) (time.Time, time.Time) {
+			start := midnight(t.In(loc))
+			return start, start.AddDate(0, 0, 1)
+		}
+	case "week":
+		bucket = func(t time.Time) (time.Time, time.Time) {
+			day := midnight(t.In(loc))
+			start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+			return start, start.AddDate(0, 0, 7)
+		}
+	case "month":
+		bucket = func(t time.Time) (time.Time, time.Time) {
+			l := t.In(loc)
+			start := time.Date(l.Year(), l.Month(), 1, 0, 0, 0, 0, loc)
+			return start, start.AddDate(0, 1, 0)
+		}
+	case "quarter":
+		bucket = func(t time.Time) (time.Time, time.Time) {
+			l := t.In(loc)
+			start := time.Date(l.Year(), l.Month()-(l.Month()-1)%3, 1, 0, 0, 0, 0, loc)
+			return start, start.AddDate(0, 3, 0)
+		}
+	default:
+		return nil, fmt.Errorf("invalid group_by %q", groupBy)
+	}
+
+	var out []octopusenergy.ConsumptionInterval
+	for _, in := range intervals {
+		start, end := bucket(in.Start)
+		if n := len(out); n > 0 && out[n-1].Start.Equal(start) {
+			out[n-1].Consumption += in.Consumption
+			continue
+		}
+		out = append(out, octopusenergy.ConsumptionInterval{Start: start, End: end, Consumption: in.Consumption})
+	}
+	return out, nil
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func (s *Server) getMeterPoint(w http.ResponseWriter, mpan string) {
+	s.mu.Lock()
+	mp, ok := s.meterPoints[mpan]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	writeJSON(w, mp)
+}
+
+type gridSupplyPointJSON struct {
+	GroupID string `json:"group_id"`
+}
+
+func (s *Server) getGridSupplyPoints(w http.ResponseWriter, r *http.Request) {
+	postcode := r.URL.Query().Get("postcode")
+
+	s.mu.Lock()
+	var results []gridSupplyPointJSON
+	if postcode != "" {
+		if id, ok := s.gridSupplyPoints[normalisePostcode(postcode)]; ok {
+			results = append(results, gridSupplyPointJSON{GroupID: id})
+		}
+	} else {
+		seen := map[string]bool{}
+		for _, id := range s.gridSupplyPoints {
+			if !seen[id] {
+				seen[id] = true
+				results = append(results, gridSupplyPointJSON{GroupID: id})
+			}
+		}
+		sort.Slice(results, func(i, j int) bool { return results[i].GroupID < results[j].GroupID })
+	}
+	s.mu.Unlock()
+
+	start, end, p, err := paginate(r, len(results), defaultPageSize, defaultPageSize)
+	if err != nil {
+		writePageError(w, err)
+		return
+	}
+	p.Results = nonNil(results[start:end])
+	writeJSON(w, p)
+}
+
+func (s *Server) getAccount(w http.ResponseWriter, number string) {
+	s.mu.Lock()
+	account, ok := s.accounts[number]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+	writeJSON(w, account)
+}
+
+// periodFilter parses the period_from and period_to query parameters.
+func periodFilter(r *http.Request) (from, to *time.Time, err error) {
+	q := r.URL.Query()
+	if v := q.Get("period_from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid period_from %q", v)
+		}
+		from = &t
+	}
+	if v := q.Get("period_to"); v != "" {
+		if from == nil {
+			return nil, nil, fmt.Errorf("period_to requires period_from")
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid period_to %q", v)
+		}
+		to = &t
+	}
+	return from, to, nil
+}
+
+func boolFilter(param string, value bool) bool {
+	if param == "" {
+		return true
+	}
+	want, err := strconv.ParseBool(param)
+	return err != nil || want == value
+}
+
+// nonNil makes sure empty results encode as [] rather than null.
+func nonNil(v interface{}) interface{} {
+	switch r := v.(type) {
+	case []productSummary:
+		if r == nil {
+			return []productSummary{}
+		}
+	case []tariffChargeJSON:
+		if r == nil {
+			return []tariffChargeJSON{}
+		}
+	case []gridSupplyPointJSON:
+		if r == nil {
+			return []gridSupplyPointJSON{}
+		}
+	}
+	return v
+}
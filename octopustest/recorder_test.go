@@ -0,0 +1,130 @@
+package octopustest_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func replayClient(t *testing.T, cassette string) *octopusenergy.Client {
+	t.Helper()
+	rec, err := octopustest.NewRecorder(filepath.Join("testdata", cassette), octopustest.RecorderModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return octopusenergy.NewClient(octopusenergy.NewConfig().
+		WithApiKey("unused-in-replay").
+		WithHTTPClient(rec.HTTPClient()),
+	)
+}
+
+func TestReplayProduct(t *testing.T) {
+	product, err := replayClient(t, "product.json").Product.Get(&octopusenergy.ProductsGetOptions{ProductCode: "AGILE-18-02-21"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tariff, ok := product.SingleRegisterElectricityTariffs["_H"]
+	if !ok {
+		t.Fatalf("expected a tariff for region _H, got %v", product.SingleRegisterElectricityTariffs)
+	}
+	if tariff.DirectDebitMonthly.Code != "E-1R-AGILE-18-02-21-H" || tariff.DirectDebitMonthly.StandardUnitRateIncVat != 13.23 {
+		t.Errorf("unexpected tariff %+v", tariff.DirectDebitMonthly)
+	}
+	if links := tariff.DirectDebitMonthly.Links; len(links) != 2 || links[1].Rel != "standard_unit_rates" {
+		t.Errorf("expected tariff links to decode, got %+v", links)
+	}
+	if product.SampleQuotes["_H"].DirectDebitMonthly.ElectricitySingleRate.AnnualCostIncVat != 47330 {
+		t.Errorf("unexpected sample quote %+v", product.SampleQuotes["_H"])
+	}
+}
+
+func TestReplayAccount(t *testing.T) {
+	account, err := replayClient(t, "account.json").Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(account.Properties) != 1 {
+		t.Fatalf("expected 1 property, got %d", len(account.Properties))
+	}
+	property := account.Properties[0]
+	if property.MovedOutAt != nil {
+		t.Errorf("expected no move out date, got %s", property.MovedOutAt)
+	}
+
+	mp := property.ElectricityMeterPoints[0]
+	if len(mp.Meters) != 2 || mp.Meters[1].Registers[0].Identifier != "1" {
+		t.Errorf("unexpected meters %+v", mp.Meters)
+	}
+	if len(mp.Agreements) != 2 || mp.Agreements[0].ValidTo == nil || mp.Agreements[1].ValidTo != nil {
+		t.Errorf("unexpected agreements %+v", mp.Agreements)
+	}
+	if gas := property.GasMeterPoints[0]; gas.MPRN != "MPRN-1" || len(gas.Meters[0].Registers) != 0 {
+		t.Errorf("unexpected gas meter point %+v", gas)
+	}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+	srv.AddAccount(octopusenergy.AccountGetOutput{Number: "A-AAAA1111"})
+	srv.AddGridSupplyPoint("SW1A 1AA", "_C")
+
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := octopustest.NewRecorder(cassette, octopustest.RecorderModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Redact("SW1A", "XX1X")
+
+	client := octopusenergy.NewClient(srv.ClientConfig().WithHTTPClient(rec.HTTPClient()))
+	if _, err := client.Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GridSupplyPoint.Get(&octopusenergy.GridSupplyPointGetOptions{Postcode: octopusenergy.String("SW1A1AA")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"A-AAAA1111", "SW1A", octopustest.DefaultAPIKey} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	srv.Close()
+
+	replay, err := octopustest.NewRecorder(cassette, octopustest.RecorderModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.Redact("SW1A", "XX1X")
+	client = octopusenergy.NewClient(octopusenergy.NewConfig().WithHTTPClient(replay.HTTPClient()))
+
+	account, err := client.Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Number != "A-00000000" {
+		t.Errorf("expected scrubbed account number, got %s", account.Number)
+	}
+	gsp, err := client.GridSupplyPoint.Get(&octopusenergy.GridSupplyPointGetOptions{Postcode: octopusenergy.String("SW1A1AA")})
+	if err != nil || len(gsp.Results) != 1 || gsp.Results[0].GroupID != "_C" {
+		t.Errorf("unexpected grid supply points %v, %v", gsp, err)
+	}
+
+	if _, err := client.Product.Get(&octopusenergy.ProductsGetOptions{ProductCode: "GO-21-05-13"}); err == nil {
+		t.Error("expected an error for a request missing from the cassette")
+	}
+}
@@ -0,0 +1,330 @@
+// Package octopustest provides an in-process fake of the Octopus Energy REST API for use in tests.
+//
+// The fake serves every endpoint the octopusenergy client calls from seedable in-memory data, paginates
+// the same way the real API does, checks Basic auth on the endpoints that need it and can be told to
+// fail requests.
+//
+//	srv := octopustest.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111", "22L333", intervals...)
+//	client := srv.Client()
+package octopustest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+const (
+	// DefaultAPIKey is the API key the server accepts unless APIKey is changed.
+	DefaultAPIKey = "sk_test_octopustest"
+
+	defaultPageSize = 100
+)
+
+// Server is a fake Octopus Energy API. Seed it with the Add methods before or during a test.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	// APIKey is the key authenticated endpoints require, if blank authentication is not checked.
+	APIKey string
+
+	// GraphQL, if set, handles requests to the GraphQL endpoint, otherwise they are not found.
+	GraphQL http.Handler
+
+	products         map[string]octopusenergy.ProductsGetOutput
+	productOrder     []string
+	tariffCharges    map[string][]octopusenergy.TariffCharge
+	consumption      map[string][]octopusenergy.ConsumptionInterval
+	gridSupplyPoints map[string]string
+	meterPoints      map[string]octopusenergy.MeterPointGetOutput
+	accounts         map[string]octopusenergy.AccountGetOutput
+	faults           []*Fault
+	requests         []*http.Request
+	productsPageSize int
+}
+
+// Fault makes matching requests fail instead of being served.
+type Fault struct {
+	// Requests whose path contains Path are affected, blank matches every request.
+	Path string
+
+	// Status code to respond with. Defaults to 500.
+	StatusCode int
+
+	// Body to respond with. Defaults to a JSON error detail, like the real API.
+	Body string
+
+	// Delay before responding, useful to test timeouts. A fault with only a Delay slows
+	// requests down without failing them.
+	Delay time.Duration
+
+	// Number of requests to affect before the fault clears itself, 0 affects every request.
+	Times int
+}
+
+// NewServer starts and returns a new, empty, Server. The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		APIKey:           DefaultAPIKey,
+		products:         map[string]octopusenergy.ProductsGetOutput{},
+		tariffCharges:    map[string][]octopusenergy.TariffCharge{},
+		consumption:      map[string][]octopusenergy.ConsumptionInterval{},
+		gridSupplyPoints: map[string]string{},
+		meterPoints:      map[string]octopusenergy.MeterPointGetOutput{},
+		accounts:         map[string]octopusenergy.AccountGetOutput{},
+		productsPageSize: defaultPageSize,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// ClientConfig returns a client config pointing at the server and authenticated with its API key.
+func (s *Server) ClientConfig() *octopusenergy.Config {
+	cfg := octopusenergy.NewConfig().WithEndpoint(s.URL)
+	if key := s.apiKey(); key != "" {
+		cfg.WithApiKey(key)
+	}
+	return cfg
+}
+
+// Client returns an octopusenergy client pointing at the server and authenticated with its API key.
+// It replaces httptest.Server's Client, which is still available as s.Server.Client().
+func (s *Server) Client() *octopusenergy.Client {
+	return octopusenergy.NewClient(s.ClientConfig())
+}
+
+// InjectFault adds a fault, faults are checked in the order they were added.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes every fault.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns every request the server has received, in order.
+func (s *Server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*http.Request, len(s.requests))
+	copy(out, s.requests)
+	return out
+}
+
+// SetProductsPageSize changes how many products are returned per page, the real API uses 100.
+func (s *Server) SetProductsPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.productsPageSize = n
+}
+
+func (s *Server) apiKey() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.APIKey
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	fault := s.matchFault(r)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.StatusCode != 0 || fault.Body != "" || fault.Delay == 0 {
+			status := fault.StatusCode
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+			body := fault.Body
+			if body == "" {
+				body = fmt.Sprintf(`{"detail": %q}`, http.StatusText(status))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			fmt.Fprint(w, body)
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "v1" {
+		writeError(w, http.StatusNotFound, "Not found.")
+		return
+	}
+
+	switch {
+	case parts[1] == "graphql" && s.GraphQL != nil:
+		s.GraphQL.ServeHTTP(w, r)
+	case parts[1] == "products" && len(parts) == 2:
+		s.listProducts(w, r)
+	case parts[1] == "products" && len(parts) == 3:
+		s.getProduct(w, r, parts[2])
+	case parts[1] == "products" && len(parts) == 6 && strings.HasSuffix(parts[3], "-tariffs"):
+		s.getTariffCharges(w, r, parts[2], strings.TrimSuffix(parts[3], "-tariffs"), parts[4], parts[5])
+	case len(parts) == 6 && strings.HasSuffix(parts[1], "-meter-points") && parts[3] == "meters" && parts[5] == "consumption":
+		if s.authorised(w, r) {
+			s.getConsumption(w, r, strings.TrimSuffix(parts[1], "-meter-points"), parts[2], parts[4])
+		}
+	case parts[1] == "electricity-meter-points" && len(parts) == 3:
+		if s.authorised(w, r) {
+			s.getMeterPoint(w, parts[2])
+		}
+	case parts[1] == "industry" && len(parts) == 3 && parts[2] == "grid-supply-points":
+		s.getGridSupplyPoints(w, r)
+	case parts[1] == "accounts" && len(parts) == 3:
+		if s.authorised(w, r) {
+			s.getAccount(w, parts[2])
+		}
+	default:
+		writeError(w, http.StatusNotFound, "Not found.")
+	}
+}
+
+// matchFault returns the first fault matching r, decrementing its count. s.mu must be held.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Path != "" && !strings.Contains(r.URL.Path, f.Path) {
+			continue
+		}
+		matched := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return &matched
+	}
+	return nil
+}
+
+func (s *Server) authorised(w http.ResponseWriter, r *http.Request) bool {
+	key := s.apiKey()
+	if key == "" {
+		return true
+	}
+
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		writeError(w, http.StatusUnauthorized, "Authentication credentials were not provided.")
+		return false
+	}
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte(key+":"))
+	if auth != want {
+		writeError(w, http.StatusUnauthorized, "Invalid API key.")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"detail": detail})
+}
+
+// page is the paginated envelope every list endpoint returns.
+type page struct {
+	Count    int         `json:"count"`
+	Next     *string     `json:"next"`
+	Previous *string     `json:"previous"`
+	Results  interface{} `json:"results"`
+}
+
+// paginate works out the slice bounds of the requested page and the next and previous links.
+func paginate(r *http.Request, total, defaultSize, maxSize int) (start, end int, p page, err error) {
+	q := r.URL.Query()
+
+	size := defaultSize
+	if v := q.Get("page_size"); v != "" {
+		if size, err = strconv.Atoi(v); err != nil || size < 1 {
+			return 0, 0, p, fmt.Errorf("invalid page_size %q", v)
+		}
+		if size > maxSize {
+			size = maxSize
+		}
+	}
+
+	number := 1
+	if v := q.Get("page"); v != "" {
+		if number, err = strconv.Atoi(v); err != nil || number < 1 {
+			return 0, 0, p, fmt.Errorf("invalid page %q", v)
+		}
+	}
+
+	start = (number - 1) * size
+	if start > 0 && start >= total {
+		return 0, 0, p, errInvalidPage
+	}
+	end = start + size
+	if end > total {
+		end = total
+	}
+
+	p.Count = total
+	if end < total {
+		p.Next = pageLink(r, number+1)
+	}
+	if number > 1 {
+		p.Previous = pageLink(r, number-1)
+	}
+	return start, end, p, nil
+}
+
+var errInvalidPage = errors.New("invalid page")
+
+func pageLink(r *http.Request, number int) *string {
+	u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+	q := r.URL.Query()
+	if number == 1 {
+		q.Del("page")
+	} else {
+		q.Set("page", strconv.Itoa(number))
+	}
+	u.RawQuery = q.Encode()
+	link := u.String()
+	return &link
+}
+
+func writePageError(w http.ResponseWriter, err error) {
+	if err == errInvalidPage {
+		writeError(w, http.StatusNotFound, "Invalid page.")
+		return
+	}
+	writeError(w, http.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,181 @@
+package octopustest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+var day = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func halfHours(n int, kwh float64) []octopusenergy.ConsumptionInterval {
+	out := make([]octopusenergy.ConsumptionInterval, n)
+	for i := range out {
+		start := day.Add(time.Duration(i) * 30 * time.Minute)
+		out[i] = octopusenergy.ConsumptionInterval{Start: start, End: start.Add(30 * time.Minute), Consumption: kwh}
+	}
+	return out
+}
+
+func TestConsumptionPagination(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111", "22L333", halfHours(96, 0.25)...)
+
+	client := srv.Client()
+
+	first, err := client.Consumption.Get(&octopusenergy.ConsumptionGetOptions{
+		MPN:          "1111111111",
+		SerialNumber: "22L333",
+		FuelType:     octopusenergy.FuelTypeElectricity,
+		PageSize:     octopusenergy.Int(40),
+		OrderBy:      octopusenergy.String("period"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Count != 96 || len(first.Results) != 40 || first.Next == "" || first.Previous != "" {
+		t.Fatalf("unexpected first page: count %d, %d results, next %q, previous %q", first.Count, len(first.Results), first.Next, first.Previous)
+	}
+	if first.Results[0].IntervalStart != "2021-03-01T00:00:00Z" {
+		t.Errorf("expected oldest first, got %s", first.Results[0].IntervalStart)
+	}
+
+	all, err := client.Consumption.GetPages(&octopusenergy.ConsumptionGetOptions{
+		MPN:          "1111111111",
+		SerialNumber: "22L333",
+		FuelType:     octopusenergy.FuelTypeElectricity,
+		PeriodFrom:   octopusenergy.Time(day.Add(24 * time.Hour)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Results) != 48 {
+		t.Errorf("expected 48 results from the second day, got %d", len(all.Results))
+	}
+
+	daily, err := client.Consumption.Get(&octopusenergy.ConsumptionGetOptions{
+		MPN:          "1111111111",
+		SerialNumber: "22L333",
+		FuelType:     octopusenergy.FuelTypeElectricity,
+		GroupBy:      octopusenergy.String("day"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(daily.Results) != 2 || daily.Results[0].Consumption != 12 {
+		t.Errorf("expected 2 days of 12 kWh, got %+v", daily.Results)
+	}
+}
+
+func TestTariffChargesGetPages(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	// more than one full page at the maximum page size, to exercise GetPages
+	var charges []octopusenergy.TariffCharge
+	for i := 0; i < 1600; i++ {
+		from := day.Add(time.Duration(i) * 30 * time.Minute)
+		charges = append(charges, octopusenergy.TariffCharge{ValueExcVat: float64(i), ValueIncVat: float64(i) * 1.05, ValidFrom: from, ValidTo: from.Add(30 * time.Minute)})
+	}
+	srv.AddTariffCharges(octopusenergy.ProductCodeAgile180221, octopusenergy.FuelTypeElectricity, "E-1R-AGILE-18-02-21-H", octopusenergy.RateStandardUnit, charges...)
+
+	res, err := srv.Client().TariffCharge.GetPages(&octopusenergy.TariffChargesGetOptions{
+		ProductCode: octopusenergy.ProductCodeAgile180221,
+		TariffCode:  "E-1R-AGILE-18-02-21-H",
+		FuelType:    octopusenergy.FuelTypeElectricity,
+		Rate:        octopusenergy.RateStandardUnit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Results) != 1600 {
+		t.Fatalf("expected 1600 charges, got %d", len(res.Results))
+	}
+
+	seen := map[time.Time]bool{}
+	for _, c := range res.Results {
+		if seen[c.ValidFrom] {
+			t.Fatalf("charge from %s returned twice", c.ValidFrom)
+		}
+		seen[c.ValidFrom] = true
+	}
+}
+
+func TestAuthAndFaults(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+	srv.AddAccount(octopusenergy.AccountGetOutput{Number: "A-AAAA1111"})
+	srv.AddGridSupplyPoint("SW1A 1AA", "_C")
+
+	unauthed := octopusenergy.NewClient(octopusenergy.NewConfig().WithEndpoint(srv.URL))
+	if _, err := unauthed.Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"}); err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Errorf("expected authentication error, got %v", err)
+	}
+
+	account, err := srv.Client().Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"})
+	if err != nil || account.Number != "A-AAAA1111" {
+		t.Fatalf("unexpected account %v, %v", account, err)
+	}
+
+	// public endpoints do not need a key
+	gsp, err := unauthed.GridSupplyPoint.Get(&octopusenergy.GridSupplyPointGetOptions{Postcode: octopusenergy.String("sw1a1aa")})
+	if err != nil || len(gsp.Results) != 1 || gsp.Results[0].GroupID != "_C" {
+		t.Fatalf("unexpected grid supply points %v, %v", gsp, err)
+	}
+
+	srv.InjectFault(octopustest.Fault{Path: "/accounts/", StatusCode: 503, Times: 1})
+	if _, err := srv.Client().Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"}); err == nil {
+		t.Error("expected injected fault")
+	}
+	if _, err := srv.Client().Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"}); err != nil {
+		t.Errorf("expected fault to clear after one request, got %v", err)
+	}
+
+	srv.InjectFault(octopustest.Fault{Delay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := srv.Client().GridSupplyPoint.GetWithContext(ctx, nil); err == nil {
+		t.Error("expected timeout from delayed response")
+	}
+}
+
+func TestProducts(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+	srv.SetProductsPageSize(2)
+
+	for _, code := range []string{"AGILE-18-02-21", "GO-21-05-13", "VAR-17-01-11"} {
+		srv.AddProduct(octopusenergy.ProductsGetOutput{Code: code, IsVariable: code != "GO-21-05-13", AvailableFrom: day})
+	}
+
+	client := srv.Client()
+
+	all, err := client.Product.ListPages(&octopusenergy.ProductsListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Results) != 3 {
+		t.Errorf("expected 3 products, got %d", len(all.Results))
+	}
+
+	variable, err := client.Product.List(&octopusenergy.ProductsListOptions{IsVariable: octopusenergy.Bool(true)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if variable.Count != 2 {
+		t.Errorf("expected 2 variable products, got %d", variable.Count)
+	}
+
+	product, err := client.Product.Get(&octopusenergy.ProductsGetOptions{ProductCode: "GO-21-05-13", TariffsActiveAt: octopusenergy.Time(day)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product.Code != "GO-21-05-13" || !product.TariffsActiveAt.Equal(day) {
+		t.Errorf("unexpected product %s active at %s", product.Code, product.TariffsActiveAt)
+	}
+}
@@ -86,7 +86,14 @@ func (s *ProductService) ListWithContext(ctx context.Context, options *ProductsL
 	}
 
 	res := ProductsListOutput{}
-	if err := s.client.sendRequest(req, true, &res); err != nil {
+	op := Operation{
+		Service:      "Product",
+		Method:       "List",
+		HTTPMethod:   http.MethodGet,
+		PathTemplate: "/v1/products/",
+		AuthRequired: true,
+	}
+	if err := s.client.sendRequest(op, req, &res); err != nil {
 		return nil, err
 	}
 
@@ -100,16 +107,19 @@ func (s *ProductService) ListPages(options *ProductsListOptions) (*ProductsListO
 
 // ListPagesWithContext same as ListPages except it takes a Context.
 func (s *ProductService) ListPagesWithContext(ctx context.Context, options *ProductsListOptions) (*ProductsListOutput, error) {
+	ctx, span := s.client.startPagesSpan(ctx, Operation{Service: "Product", Method: "ListPages", PathTemplate: "/v1/products/"})
+	defer span.End()
+
 	options.Page = nil
 
 	fullResp := ProductsListOutput{}
 	var lastPage bool
-	var i int
+	i := 1
 
 	for !lastPage {
 		page, err := s.ListWithContext(ctx, options)
 		if err != nil {
-			return nil, err
+			return nil, spanError(span, err)
 		}
 		fullResp.Count = page.Count
 		fullResp.Results = append(fullResp.Results, page.Results...)
@@ -120,6 +130,7 @@ func (s *ProductService) ListPagesWithContext(ctx context.Context, options *Prod
 		options.Page = Int(i)
 	}
 
+	endPagesSpan(span, len(fullResp.Results))
 	return &fullResp, nil
 }
 
@@ -182,7 +193,7 @@ type TariffDirectDebit struct {
 		Href   string `json:"href"`
 		Method string `json:"method"`
 		Rel    string
-	} `json:"direct_debit_monthly"`
+	} `json:"links"`
 }
 
 type SampleQuote struct {
@@ -244,7 +255,14 @@ func (s *ProductService) GetWithContext(ctx context.Context, options *ProductsGe
 	}
 
 	res := ProductsGetOutput{}
-	if err := s.client.sendRequest(req, true, &res); err != nil {
+	op := Operation{
+		Service:      "Product",
+		Method:       "Get",
+		HTTPMethod:   http.MethodGet,
+		PathTemplate: "/v1/products/{product_code}",
+		AuthRequired: true,
+	}
+	if err := s.client.sendRequest(op, req, &res); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,81 @@
+package octopusenergy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TariffChargesProductGetOptions is the options for GetForProduct.
+type TariffChargesProductGetOptions struct {
+	// The code of the product, example "AGILE-18-02-21" or "AGILE-OUTGOING-19-05-13".
+	ProductCode string
+
+	// The grid supply point group of the region, example "_C".
+	Region string
+
+	// Fueltype: electricity or gas
+	FuelType FuelType
+
+	// The type of charge
+	Rate Rate
+
+	// Show charges active from the given datetime (inclusive).
+	PeriodFrom *time.Time
+
+	// Show charges active up to the given datetime (exclusive).
+	PeriodTo *time.Time
+}
+
+// TariffChargesProductGetOutput is the returned struct from GetForProduct.
+type TariffChargesProductGetOutput struct {
+	// The code of the product's single register tariff for the region.
+	TariffCode string
+
+	Results []TariffCharge
+}
+
+// GetForProduct looks up a product's single register tariff for a region and fetches all of its
+// charges, so rates can be fetched from a product code alone, for both import and export products.
+func (s *TariffChargeService) GetForProduct(options *TariffChargesProductGetOptions) (*TariffChargesProductGetOutput, error) {
+	return s.GetForProductWithContext(context.Background(), options)
+}
+
+// GetForProductWithContext same as GetForProduct except it takes a Context.
+func (s *TariffChargeService) GetForProductWithContext(ctx context.Context, options *TariffChargesProductGetOptions) (*TariffChargesProductGetOutput, error) {
+	product, err := s.client.Product.GetWithContext(ctx, &ProductsGetOptions{ProductCode: options.ProductCode, TariffsActiveAt: options.PeriodFrom})
+	if err != nil {
+		return nil, err
+	}
+
+	tariffs := product.SingleRegisterElectricityTariffs
+	if options.FuelType == FuelTypeGas {
+		tariffs = product.SingleRegisterGasTariffs
+	}
+	region := options.Region
+	if !strings.HasPrefix(region, "_") {
+		region = "_" + region
+	}
+	tariff, ok := tariffs[strings.ToUpper(region)]
+	if !ok {
+		return nil, fmt.Errorf("product %s has no %s tariff for region %s", options.ProductCode, options.FuelType, options.Region)
+	}
+	code := tariff.DirectDebitMonthly.Code
+	if code == "" {
+		code = tariff.DirectDebitQuarterly.Code
+	}
+
+	res, err := s.GetPagesWithContext(ctx, &TariffChargesGetOptions{
+		ProductCode: options.ProductCode,
+		TariffCode:  code,
+		FuelType:    options.FuelType,
+		Rate:        options.Rate,
+		PeriodFrom:  options.PeriodFrom,
+		PeriodTo:    options.PeriodTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TariffChargesProductGetOutput{TariffCode: code, Results: res.Results}, nil
+}
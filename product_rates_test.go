@@ -0,0 +1,48 @@
+package octopusenergy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestTariffChargeGetForProduct(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	srv.AddProduct(octopusenergy.ProductsGetOutput{
+		Code:          "AGILE-OUTGOING-19-05-13",
+		AvailableFrom: from.AddDate(-1, 0, 0),
+		SingleRegisterElectricityTariffs: map[string]octopusenergy.Tariff{
+			"_C": {DirectDebitMonthly: octopusenergy.TariffDirectDebit{Code: "E-1R-AGILE-OUTGOING-19-05-13-C"}},
+		},
+	})
+	srv.AddTariffCharges("AGILE-OUTGOING-19-05-13", octopusenergy.FuelTypeElectricity, "E-1R-AGILE-OUTGOING-19-05-13-C", octopusenergy.RateStandardUnit,
+		octopusenergy.TariffCharge{ValueExcVat: 5, ValueIncVat: 5, ValidFrom: from, ValidTo: from.Add(30 * time.Minute)},
+		octopusenergy.TariffCharge{ValueExcVat: 7, ValueIncVat: 7, ValidFrom: from.Add(30 * time.Minute), ValidTo: from.Add(time.Hour)},
+	)
+
+	options := octopusenergy.TariffChargesProductGetOptions{
+		ProductCode: "AGILE-OUTGOING-19-05-13",
+		Region:      "C",
+		FuelType:    octopusenergy.FuelTypeElectricity,
+		Rate:        octopusenergy.RateStandardUnit,
+		PeriodFrom:  octopusenergy.Time(from),
+		PeriodTo:    octopusenergy.Time(from.Add(time.Hour)),
+	}
+	res, err := srv.Client().TariffCharge.GetForProduct(&options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TariffCode != "E-1R-AGILE-OUTGOING-19-05-13-C" || len(res.Results) != 2 {
+		t.Errorf("unexpected charges %+v", res)
+	}
+
+	options.Region = "_H"
+	if _, err := srv.Client().TariffCharge.GetForProduct(&options); err == nil {
+		t.Error("expected an error for a region without a tariff")
+	}
+}
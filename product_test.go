@@ -0,0 +1,35 @@
+package octopusenergy_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+func TestTariffDirectDebitLinks(t *testing.T) {
+	data := `{
+		"direct_debit_monthly": {
+			"code": "E-1R-AGILE-18-02-21-A",
+			"standing_charge_inc_vat": 21,
+			"links": [{
+				"href": "https://api.octopus.energy/v1/products/AGILE-18-02-21/electricity-tariffs/E-1R-AGILE-18-02-21-A/standing-charges/",
+				"method": "GET",
+				"rel": "standing_charges"
+			}]
+		}
+	}`
+
+	var tariff octopusenergy.Tariff
+	if err := json.Unmarshal([]byte(data), &tariff); err != nil {
+		t.Fatal(err)
+	}
+
+	links := tariff.DirectDebitMonthly.Links
+	if len(links) != 1 {
+		t.Fatalf("expected the tariff's links to be decoded, got %+v", tariff.DirectDebitMonthly)
+	}
+	if links[0].Rel != "standing_charges" || links[0].Method != "GET" || links[0].Href == "" {
+		t.Errorf("unexpected link %+v", links[0])
+	}
+}
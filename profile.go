@@ -0,0 +1,290 @@
+package octopusenergy
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configPathEnvKey = "OCTOPUS_ENERGY_CONFIG"
+	profileEnvKey    = "OCTOPUS_ENERGY_PROFILE"
+
+	defaultProfile = "default"
+)
+
+// ErrInvalidConfig is wrapped by every error returned from Profile.Validate, and by LoadProfile
+// when a config file or environment variable can not be parsed.
+var ErrInvalidConfig = errors.New("invalid config")
+
+// ConfigFile is the layout of a YAML or TOML config file, which holds one or more named profiles.
+//
+//	default_profile: home
+//	profiles:
+//	  home:
+//	    api_key: sk_live_...
+//	    account_number: A-AAAA1111
+//	    region: _C
+//	    electricity:
+//	      mpn: "1111111111111"
+//	      serial_number: 11A1111111
+//	    retry:
+//	      max_attempts: 3
+//	      backoff: 1s
+type ConfigFile struct {
+	// Profile used when none is asked for. Defaults to "default".
+	DefaultProfile string `yaml:"default_profile" toml:"default_profile"`
+
+	Profiles map[string]Profile `yaml:"profiles" toml:"profiles"`
+}
+
+// Profile is the settings for one account.
+type Profile struct {
+	// The name of the profile in its config file.
+	Name string `yaml:"-" toml:"-"`
+
+	APIKey   string `yaml:"api_key" toml:"api_key"`
+	Endpoint string `yaml:"endpoint" toml:"endpoint"`
+
+	// Account number used when a command is not given one, example A-AAAA1111.
+	AccountNumber string `yaml:"account_number" toml:"account_number"`
+
+	// The grid supply point group ID of the property, example "_C".
+	Region string `yaml:"region" toml:"region"`
+
+	Electricity ProfileMeter `yaml:"electricity" toml:"electricity"`
+	Gas         ProfileMeter `yaml:"gas" toml:"gas"`
+
+	Cache ProfileCache `yaml:"cache" toml:"cache"`
+	Retry ProfileRetry `yaml:"retry" toml:"retry"`
+}
+
+// ProfileMeter is the default meter of a fuel type.
+type ProfileMeter struct {
+	// The meter point's MPAN for electricity, or MPRN for gas.
+	MPN          string `yaml:"mpn" toml:"mpn"`
+	SerialNumber string `yaml:"serial_number" toml:"serial_number"`
+}
+
+// ProfileCache configures caching of GET responses, a TTL of 0 disables it.
+type ProfileCache struct {
+	TTL Duration `yaml:"ttl" toml:"ttl"`
+}
+
+// ProfileRetry configures retrying failed requests, a MaxAttempts of 0 or 1 disables it.
+type ProfileRetry struct {
+	MaxAttempts int      `yaml:"max_attempts" toml:"max_attempts"`
+	Backoff     Duration `yaml:"backoff" toml:"backoff"`
+}
+
+// Duration is a time.Duration written in config files as a string, example "30s" or "5m".
+type Duration time.Duration
+
+// UnmarshalText parses a duration string.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText formats the duration as a string.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// LoadProfileOptions is the options for LoadProfile.
+type LoadProfileOptions struct {
+	// Path of a config file, ending .yaml, .yml or .toml. Defaults to OCTOPUS_ENERGY_CONFIG, then
+	// octopusenergy.yaml in the user's config directory if it exists. Without a file, the profile is
+	// built from the environment alone.
+	Path *string
+
+	// Name of the profile to load. Defaults to OCTOPUS_ENERGY_PROFILE, then the file's default profile.
+	Profile *string
+
+	// Looks up environment variables, defaults to os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+}
+
+// profileEnv maps environment variables to the profile setting they override.
+var profileEnv = []struct {
+	key string
+	set func(p *Profile, v string) error
+}{
+	{apiKeyEnvKey, func(p *Profile, v string) error { p.APIKey = v; return nil }},
+	{"OCTOPUS_ENERGY_ENDPOINT", func(p *Profile, v string) error { p.Endpoint = v; return nil }},
+	{"OCTOPUS_ENERGY_ACCOUNT_NUMBER", func(p *Profile, v string) error { p.AccountNumber = v; return nil }},
+	{"OCTOPUS_ENERGY_REGION", func(p *Profile, v string) error { p.Region = v; return nil }},
+	{"OCTOPUS_ENERGY_MPAN", func(p *Profile, v string) error { p.Electricity.MPN = v; return nil }},
+	{"OCTOPUS_ENERGY_ELECTRICITY_SERIAL_NUMBER", func(p *Profile, v string) error { p.Electricity.SerialNumber = v; return nil }},
+	{"OCTOPUS_ENERGY_MPRN", func(p *Profile, v string) error { p.Gas.MPN = v; return nil }},
+	{"OCTOPUS_ENERGY_GAS_SERIAL_NUMBER", func(p *Profile, v string) error { p.Gas.SerialNumber = v; return nil }},
+	{"OCTOPUS_ENERGY_CACHE_TTL", func(p *Profile, v string) error { return p.Cache.TTL.UnmarshalText([]byte(v)) }},
+	{"OCTOPUS_ENERGY_RETRY_MAX_ATTEMPTS", func(p *Profile, v string) (err error) {
+		p.Retry.MaxAttempts, err = strconv.Atoi(v)
+		return err
+	}},
+	{"OCTOPUS_ENERGY_RETRY_BACKOFF", func(p *Profile, v string) error { return p.Retry.Backoff.UnmarshalText([]byte(v)) }},
+}
+
+// LoadProfile reads a profile from a config file, overrides its settings with any OCTOPUS_ENERGY_*
+// environment variables that are set, and validates it.
+func LoadProfile(options *LoadProfileOptions) (*Profile, error) {
+	if options == nil {
+		options = &LoadProfileOptions{}
+	}
+	lookupEnv := options.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	path, required := "", false
+	switch {
+	case options.Path != nil:
+		path, required = *options.Path, true
+	default:
+		if v, ok := lookupEnv(configPathEnvKey); ok && v != "" {
+			path, required = v, true
+		} else if dir, err := os.UserConfigDir(); err == nil {
+			path = filepath.Join(dir, "octopusenergy.yaml")
+		}
+	}
+
+	file := ConfigFile{}
+	if path != "" {
+		var err error
+		file, err = readConfigFile(path)
+		if err != nil && (required || !errors.Is(err, os.ErrNotExist)) {
+			return nil, err
+		}
+	}
+
+	name := file.DefaultProfile
+	if v, ok := lookupEnv(profileEnvKey); ok && v != "" {
+		name = v
+	}
+	if options.Profile != nil {
+		name = *options.Profile
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+
+	profile, ok := file.Profiles[name]
+	if !ok && (len(file.Profiles) > 0 || name != defaultProfile) {
+		return nil, fmt.Errorf("%w: no profile %q in %s, have %v", ErrInvalidConfig, name, path, file.profileNames())
+	}
+	profile.Name = name
+
+	for _, e := range profileEnv {
+		if v, ok := lookupEnv(e.key); ok && v != "" {
+			if err := e.set(&profile, v); err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, e.key, err)
+			}
+		}
+	}
+
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// readConfigFile decodes a YAML or TOML config file, chosen by its extension.
+func readConfigFile(path string) (ConfigFile, error) {
+	file := ConfigFile{}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return file, err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &file)
+	case ".toml":
+		err = toml.Unmarshal(b, &file)
+	default:
+		return file, fmt.Errorf("%w: unsupported config file type %q, expected .yaml, .yml or .toml", ErrInvalidConfig, ext)
+	}
+	if err != nil {
+		return file, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+	return file, nil
+}
+
+func (f ConfigFile) profileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	accountNumberPattern = regexp.MustCompile(`^A-[0-9A-F]{8}$`)
+	mpanPattern          = regexp.MustCompile(`^[0-9]{13}$`)
+	mprnPattern          = regexp.MustCompile(`^[0-9]{6,10}$`)
+	regionPattern        = regexp.MustCompile(`^_[A-HJ-NP]$`)
+)
+
+// Validate checks every setting of the profile, returning all the problems found in one error.
+// Blank settings are not checked, the API key is only required by endpoints which need it.
+func (p *Profile) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	if p.Endpoint != "" {
+		_, err := parseEndpoint(p.Endpoint)
+		check(err == nil, "%v", err)
+	}
+	check(p.AccountNumber == "" || accountNumberPattern.MatchString(p.AccountNumber), "invalid account number %q, expected the form A-AAAA1111", p.AccountNumber)
+	check(p.Region == "" || regionPattern.MatchString(p.Region), "invalid region %q, expected a grid supply point group such as _C", p.Region)
+	check(p.Electricity.MPN == "" || mpanPattern.MatchString(p.Electricity.MPN), "invalid MPAN %q, expected 13 digits", p.Electricity.MPN)
+	check(p.Gas.MPN == "" || mprnPattern.MatchString(p.Gas.MPN), "invalid MPRN %q, expected 6 to 10 digits", p.Gas.MPN)
+	check(p.Electricity.SerialNumber == "" || p.Electricity.MPN != "", "electricity serial number given without an MPAN")
+	check(p.Gas.SerialNumber == "" || p.Gas.MPN != "", "gas serial number given without an MPRN")
+	check(p.Cache.TTL >= 0, "cache TTL must not be negative")
+	check(p.Retry.MaxAttempts >= 0, "retry max attempts must not be negative")
+	check(p.Retry.Backoff >= 0, "retry backoff must not be negative")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: profile %q: %s", ErrInvalidConfig, p.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Config returns a client config for the profile, with its retry and cache settings as middleware.
+func (p *Profile) Config() *Config {
+	cfg := NewConfig()
+	if p.APIKey != "" {
+		cfg.WithApiKey(p.APIKey)
+	}
+	if p.Endpoint != "" {
+		cfg.WithEndpoint(p.Endpoint)
+	}
+	if p.Retry.MaxAttempts > 1 {
+		cfg.WithMiddleware(Retry(p.Retry.MaxAttempts, time.Duration(p.Retry.Backoff)))
+	}
+	if p.Cache.TTL > 0 {
+		cfg.WithMiddleware(Cache(time.Duration(p.Cache.TTL)))
+	}
+	return cfg
+}
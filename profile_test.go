@@ -0,0 +1,164 @@
+package octopusenergy_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+const yamlConfig = `default_profile: home
+profiles:
+  home:
+    api_key: sk_test_home
+    account_number: A-AAAA1111
+    region: _C
+    electricity:
+      mpn: "1111111111111"
+      serial_number: 11A1111111
+    retry:
+      max_attempts: 3
+      backoff: 10ms
+  office:
+    api_key: sk_test_office
+    account_number: A-BBBB2222
+`
+
+const tomlConfig = `default_profile = "home"
+
+[profiles.home]
+api_key = "sk_test_home"
+account_number = "A-AAAA1111"
+
+[profiles.home.gas]
+mpn = "2222222222"
+serial_number = "G4A00000000000"
+
+[profiles.home.cache]
+ttl = "5m"
+`
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	yamlPath := writeConfig(t, "octopus.yaml", yamlConfig)
+
+	home, err := octopusenergy.LoadProfile(&octopusenergy.LoadProfileOptions{
+		Path:      octopusenergy.String(yamlPath),
+		LookupEnv: env(map[string]string{"OCTOPUS_ENERGY_ELECTRICITY_SERIAL_NUMBER": "22B2222222"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if home.Name != "home" || home.AccountNumber != "A-AAAA1111" || home.Region != "_C" || home.Electricity.MPN != "1111111111111" {
+		t.Errorf("unexpected profile %+v", home)
+	}
+	if home.Electricity.SerialNumber != "22B2222222" {
+		t.Errorf("expected environment to override serial number, got %s", home.Electricity.SerialNumber)
+	}
+	if home.Retry.MaxAttempts != 3 || time.Duration(home.Retry.Backoff) != 10*time.Millisecond {
+		t.Errorf("unexpected retry settings %+v", home.Retry)
+	}
+
+	office, err := octopusenergy.LoadProfile(&octopusenergy.LoadProfileOptions{
+		LookupEnv: env(map[string]string{"OCTOPUS_ENERGY_CONFIG": yamlPath, "OCTOPUS_ENERGY_PROFILE": "office"}),
+	})
+	if err != nil || office.APIKey != "sk_test_office" {
+		t.Fatalf("unexpected office profile %+v, %v", office, err)
+	}
+
+	fromToml, err := octopusenergy.LoadProfile(&octopusenergy.LoadProfileOptions{
+		Path:      octopusenergy.String(writeConfig(t, "octopus.toml", tomlConfig)),
+		LookupEnv: env(nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromToml.Gas.MPN != "2222222222" || time.Duration(fromToml.Cache.TTL) != 5*time.Minute {
+		t.Errorf("unexpected toml profile %+v", fromToml)
+	}
+
+	missing, err := octopusenergy.LoadProfile(&octopusenergy.LoadProfileOptions{
+		Path:      octopusenergy.String(filepath.Join(t.TempDir(), "missing.yaml")),
+		LookupEnv: env(nil),
+	})
+	if err == nil {
+		t.Errorf("expected an error for a missing config file, got %+v", missing)
+	}
+}
+
+func TestLoadProfileValidation(t *testing.T) {
+	path := writeConfig(t, "octopus.yaml", yamlConfig)
+
+	for name, vars := range map[string]map[string]string{
+		"unknown profile": {"OCTOPUS_ENERGY_PROFILE": "holiday"},
+		"account number":  {"OCTOPUS_ENERGY_ACCOUNT_NUMBER": "12345"},
+		"mpan":            {"OCTOPUS_ENERGY_MPAN": "123"},
+		"region":          {"OCTOPUS_ENERGY_REGION": "_I"},
+		"endpoint":        {"OCTOPUS_ENERGY_ENDPOINT": "api.octopus.energy"},
+		"retry attempts":  {"OCTOPUS_ENERGY_RETRY_MAX_ATTEMPTS": "three"},
+	} {
+		_, err := octopusenergy.LoadProfile(&octopusenergy.LoadProfileOptions{Path: octopusenergy.String(path), LookupEnv: env(vars)})
+		if !errors.Is(err, octopusenergy.ErrInvalidConfig) {
+			t.Errorf("%s: expected invalid config, got %v", name, err)
+		}
+	}
+}
+
+func TestInvalidEndpoint(t *testing.T) {
+	cfg := octopusenergy.NewConfig().WithEndpoint("://api.octopus.energy")
+	if cfg.Err() == nil {
+		t.Error("expected config error for invalid endpoint")
+	}
+
+	client := octopusenergy.NewClient(&octopusenergy.Config{Endpoint: octopusenergy.String("localhost:8080")})
+	if client.Err() == nil || !strings.Contains(client.Err().Error(), "invalid endpoint") {
+		t.Fatalf("expected client error for invalid endpoint, got %v", client.Err())
+	}
+	if _, err := client.GridSupplyPoint.Get(nil); err != client.Err() {
+		t.Errorf("expected requests to fail with the config error, got %v", err)
+	}
+}
+
+func TestProfileRetryAndCache(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+	srv.AddGridSupplyPoint("SW1A 1AA", "_C")
+	srv.InjectFault(octopustest.Fault{Path: "grid-supply-points", StatusCode: 503, Times: 2})
+
+	profile := octopusenergy.Profile{
+		Endpoint: srv.URL,
+		Retry:    octopusenergy.ProfileRetry{MaxAttempts: 3, Backoff: octopusenergy.Duration(time.Millisecond)},
+		Cache:    octopusenergy.ProfileCache{TTL: octopusenergy.Duration(time.Minute)},
+	}
+	client := octopusenergy.NewClient(profile.Config())
+
+	for i := 0; i < 2; i++ {
+		gsp, err := client.GridSupplyPoint.Get(nil)
+		if err != nil || len(gsp.Results) != 1 {
+			t.Fatalf("unexpected grid supply points %v, %v", gsp, err)
+		}
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("expected 2 failed attempts, 1 success and a cached response, got %d requests", n)
+	}
+}
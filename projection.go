@@ -0,0 +1,422 @@
+package octopusenergy
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// defaultProjectionConfidence is how likely the bill is to fall within the projected range, the 10th
+// to 90th percentile.
+const defaultProjectionConfidence = 0.8
+
+// ProjectionOptions is the options for ProjectBill.
+type ProjectionOptions struct {
+	// The billing period, from (inclusive) to (exclusive).
+	From time.Time
+	To   time.Time
+
+	// The time the projection is made. Default is now.
+	At time.Time
+
+	// Recent half-hourly consumption up to At, including the billing period so far. Complete days
+	// are also used as usage patterns.
+	Consumption []ConsumptionInterval
+
+	// Earlier consumption to learn usage patterns from, for example the same period last year.
+	History []ConsumptionInterval
+
+	// Unit rates and standing charges, in any order, for example from TariffChargeService.GetTimeline.
+	// Unit rates not yet published, such as Agile rates beyond tomorrow, are estimated as the average
+	// rate at the same time of day over the last week of published rates.
+	UnitRates       []TariffCharge
+	StandingCharges []TariffCharge
+
+	// The location days are in. Default is UKLocation.
+	Location *time.Location
+
+	// How likely the bill is to fall within the projected range, between 0 and 1. Default is 0.8.
+	Confidence float64
+}
+
+// BillProjection is a projection of a billing period's consumption and cost. Costs are in pence and
+// include standing charges.
+type BillProjection struct {
+	// The billing period, from (inclusive) to (exclusive).
+	From time.Time
+	To   time.Time
+
+	// The end of the consumption to date, what follows is projected.
+	DataTo time.Time
+
+	// The consumption and cost of the period to date.
+	ConsumptionToDate float64
+	CostToDateExcVat  float64
+	CostToDateIncVat  float64
+
+	// The expected consumption of the whole period, and the range it is expected to be within.
+	Consumption     float64
+	ConsumptionLow  float64
+	ConsumptionHigh float64
+
+	// The expected cost of the whole period, and the range it is expected to be within including VAT.
+	CostExcVat     float64
+	CostIncVat     float64
+	CostLowIncVat  float64
+	CostHighIncVat float64
+
+	// Whether unit rates for part of the period were not yet published and were estimated.
+	EstimatedRates bool
+
+	// Consumption to date that had no unit rate covering its start time and so was not priced.
+	Unpriced []ConsumptionInterval
+}
+
+// usageDay is the half-hourly consumption of a complete day, by time of day.
+type usageDay struct {
+	weekend bool
+	slots   [48]float64
+}
+
+// ProjectBill projects a billing period's consumption and cost from its consumption to date. The rest
+// of the period is priced as if each remaining day repeated each complete day of past usage, weekdays
+// from weekdays and weekends from weekends, giving the expected cost and its spread. The range assumes
+// days are independent of each other.
+func ProjectBill(options ProjectionOptions) (*BillProjection, error) {
+	if !options.From.Before(options.To) {
+		return nil, errors.New("period from must be before period to")
+	}
+	if len(options.UnitRates) == 0 {
+		return nil, errors.New("no unit rates to project with")
+	}
+	loc := options.Location
+	if loc == nil {
+		loc = UKLocation()
+	}
+	at := options.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	confidence := options.Confidence
+	if confidence <= 0 || confidence >= 1 {
+		confidence = defaultProjectionConfidence
+	}
+
+	p := BillProjection{From: options.From, To: options.To, DataTo: options.From}
+	consumption := sortedIntervals(options.Consumption)
+	var toDate []ConsumptionInterval
+	for _, in := range consumption {
+		if in.Start.Before(options.From) || !in.Start.Before(options.To) || !in.Start.Before(at) {
+			continue
+		}
+		toDate = append(toDate, in)
+		if in.End.After(p.DataTo) {
+			p.DataTo = in.End
+		}
+	}
+	cost := CostConsumption(toDate, options.UnitRates)
+	p.ConsumptionToDate = cost.Consumption
+	p.CostToDateExcVat, p.CostToDateIncVat = cost.CostExcVat, cost.CostIncVat
+	p.Unpriced = cost.Unpriced
+
+	samples := usageDays(sortedIntervals(append(append([]ConsumptionInterval{}, options.History...), consumption...)), loc)
+	if len(samples) == 0 && p.DataTo.Before(options.To) {
+		return nil, errors.New("no complete days of consumption to project from")
+	}
+
+	// standing charges are known, so are added to the date and the projection alike
+	var standingExc, standingInc float64
+	standing := NewChargeIndex(options.StandingCharges)
+	var last TariffCharge
+	for day := startOfDay(options.From, loc); day.Before(options.To); day = day.AddDate(0, 0, 1) {
+		from := day
+		if from.Before(options.From) {
+			from = options.From
+		}
+		if c, ok := standing.At(from); ok {
+			last = c
+		}
+		standingExc += last.ValueExcVat
+		standingInc += last.ValueIncVat
+		if from.Before(p.DataTo) {
+			p.CostToDateExcVat += last.ValueExcVat
+			p.CostToDateIncVat += last.ValueIncVat
+		}
+	}
+
+	rates := newProjectionRates(options.UnitRates, loc)
+	var energy, energyVar, costExc, costInc, costVar float64
+	start := p.DataTo.Truncate(30 * time.Minute)
+	for day := startOfDay(start, loc); day.Before(options.To); day = day.AddDate(0, 0, 1) {
+		from, to := day, day.AddDate(0, 0, 1)
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(options.To) {
+			to = options.To
+		}
+		days := sameKind(samples, isWeekend(day))
+
+		e := make([]float64, len(days))
+		exc := make([]float64, len(days))
+		inc := make([]float64, len(days))
+		for t := from; t.Before(to); t = t.Add(30 * time.Minute) {
+			rate, estimated := rates.at(t)
+			p.EstimatedRates = p.EstimatedRates || estimated
+			slot := slotOfDay(t, loc)
+			for i, d := range days {
+				e[i] += d.slots[slot]
+				exc[i] += d.slots[slot] * rate.ValueExcVat
+				inc[i] += d.slots[slot] * rate.ValueIncVat
+			}
+		}
+		energy += mean(e)
+		energyVar += variance(e)
+		costExc += mean(exc)
+		costInc += mean(inc)
+		costVar += variance(inc)
+	}
+
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	spread := func(expected, v float64) (float64, float64) {
+		return math.Max(0, expected-z*math.Sqrt(v)), expected + z*math.Sqrt(v)
+	}
+	p.Consumption = p.ConsumptionToDate + energy
+	low, high := spread(energy, energyVar)
+	p.ConsumptionLow, p.ConsumptionHigh = p.ConsumptionToDate+low, p.ConsumptionToDate+high
+
+	unitToDateExc, unitToDateInc := cost.CostExcVat, cost.CostIncVat
+	p.CostExcVat = unitToDateExc + costExc + standingExc
+	p.CostIncVat = unitToDateInc + costInc + standingInc
+	low, high = spread(costInc, costVar)
+	p.CostLowIncVat, p.CostHighIncVat = unitToDateInc+low+standingInc, unitToDateInc+high+standingInc
+	return &p, nil
+}
+
+// usageDays returns each complete local day of consumption by time of day. Intervals must be ordered
+// by start time, duplicates are skipped.
+func usageDays(intervals []ConsumptionInterval, loc *time.Location) []usageDay {
+	var out []usageDay
+	var day time.Time
+	var d usageDay
+	var covered time.Duration
+	end := func() {
+		// allow for a missing reading or two, and the short day when the clocks go forward
+		if covered >= 22*time.Hour {
+			out = append(out, d)
+		}
+	}
+
+	seen := map[int64]bool{}
+	for _, in := range intervals {
+		if seen[in.Start.Unix()] {
+			continue
+		}
+		seen[in.Start.Unix()] = true
+
+		if start := startOfDay(in.Start, loc); !start.Equal(day) {
+			end()
+			day, d, covered = start, usageDay{weekend: isWeekend(start)}, 0
+		}
+		d.slots[slotOfDay(in.Start, loc)] += in.Consumption
+		covered += in.End.Sub(in.Start)
+	}
+	end()
+	return out
+}
+
+// sameKind returns the days that are weekends if weekend is true, or weekdays if not, or all of them
+// if there are too few of that kind.
+func sameKind(days []usageDay, weekend bool) []usageDay {
+	var out []usageDay
+	for _, d := range days {
+		if d.weekend == weekend {
+			out = append(out, d)
+		}
+	}
+	if len(out) < 2 {
+		return days
+	}
+	return out
+}
+
+// slotOfDay returns the half hour of the local day t is in, from 0 to 47.
+func slotOfDay(t time.Time, loc *time.Location) int {
+	local := t.In(loc)
+	return local.Hour()*2 + local.Minute()/30
+}
+
+func variance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return sum / float64(len(values)-1)
+}
+
+// projectionRates finds the unit rate at a time, estimating rates that are not yet published.
+type projectionRates struct {
+	sorted ChargeIndex
+	loc    *time.Location
+
+	// the average rate at each half hour of the day over the last week of published rates
+	estimates [48]TariffCharge
+}
+
+func newProjectionRates(charges []TariffCharge, loc *time.Location) *projectionRates {
+	r := projectionRates{sorted: NewChargeIndex(charges), loc: loc}
+
+	var published time.Time
+	for _, c := range r.sorted {
+		if c.ValidTo.After(published) {
+			published = c.ValidTo
+		}
+	}
+	var counts [48]float64
+	for t := published.Add(-7 * 24 * time.Hour); t.Before(published); t = t.Add(30 * time.Minute) {
+		c, ok := r.sorted.At(t)
+		if !ok {
+			continue
+		}
+		slot := slotOfDay(t, loc)
+		r.estimates[slot].ValueExcVat += c.ValueExcVat
+		r.estimates[slot].ValueIncVat += c.ValueIncVat
+		counts[slot]++
+	}
+	for i := range r.estimates {
+		if counts[i] > 0 {
+			r.estimates[i].ValueExcVat /= counts[i]
+			r.estimates[i].ValueIncVat /= counts[i]
+		}
+	}
+	return &r
+}
+
+// at returns the rate at t and whether it was estimated.
+func (r *projectionRates) at(t time.Time) (TariffCharge, bool) {
+	if c, ok := r.sorted.At(t); ok {
+		return c, false
+	}
+	return r.estimates[slotOfDay(t, r.loc)], true
+}
+
+// BillProjectionGetOptions is the options for GetBillProjection.
+type BillProjectionGetOptions struct {
+	// The meter point to project, for example from HouseholdService.Get.
+	MeterPoint HouseholdMeterPoint
+
+	// The billing period, from (inclusive) to (exclusive). Default is the calendar month of At.
+	PeriodFrom *time.Time
+	PeriodTo   *time.Time
+
+	// The time the projection is made. Default is now.
+	At *time.Time
+
+	// The location days and months are in. Default is UKLocation.
+	Location *time.Location
+
+	// How likely the bill is to fall within the projected range, between 0 and 1. Default is 0.8.
+	Confidence float64
+}
+
+// GetBillProjection fetches a meter point's consumption this billing period, the last four weeks and
+// the same period last year, and the rates of its tariffs, and projects the period's bill. See
+// ProjectBill.
+func (s *HouseholdService) GetBillProjection(options *BillProjectionGetOptions) (*BillProjection, error) {
+	return s.GetBillProjectionWithContext(context.Background(), options)
+}
+
+// GetBillProjectionWithContext same as GetBillProjection except it takes a Context.
+func (s *HouseholdService) GetBillProjectionWithContext(ctx context.Context, options *BillProjectionGetOptions) (*BillProjection, error) {
+	mp := options.MeterPoint
+	co := mp.ConsumptionOptions()
+	if co == nil {
+		return nil, errors.New("meter point has no meter with consumption data")
+	}
+	loc := options.Location
+	if loc == nil {
+		loc = UKLocation()
+	}
+	at := time.Now()
+	if options.At != nil {
+		at = *options.At
+	}
+	local := at.In(loc)
+	from := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, loc)
+	if options.PeriodFrom != nil {
+		from = *options.PeriodFrom
+	}
+	to := from.AddDate(0, 1, 0)
+	if options.PeriodTo != nil {
+		to = *options.PeriodTo
+	}
+
+	consumption := func(from, to time.Time) ([]ConsumptionInterval, error) {
+		res, err := s.client.Consumption.GetPagesWithContext(ctx, &ConsumptionGetOptions{
+			MPN:          co.MPN,
+			SerialNumber: co.SerialNumber,
+			FuelType:     co.FuelType,
+			PeriodFrom:   Time(from),
+			PeriodTo:     Time(to),
+		})
+		if isNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return res.Intervals()
+	}
+	recentFrom := at.AddDate(0, 0, -28)
+	if from.Before(recentFrom) {
+		recentFrom = from
+	}
+	recent, err := consumption(recentFrom, at)
+	if err != nil {
+		return nil, err
+	}
+	lastYear, err := consumption(from.AddDate(-1, 0, 0), to.AddDate(-1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	charges := func(rate Rate, from time.Time) ([]TariffCharge, error) {
+		res, err := s.client.TariffCharge.GetTimelineWithContext(ctx, &TariffChargesTimelineGetOptions{
+			Agreements: mp.Timeline(),
+			FuelType:   mp.FuelType,
+			Rate:       rate,
+			PeriodFrom: from,
+			PeriodTo:   to,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.Results, nil
+	}
+	// a week before the period so unpublished rates can be estimated early in it
+	unitRates, err := charges(RateStandardUnit, from.AddDate(0, 0, -7))
+	if err != nil {
+		return nil, err
+	}
+	standingCharges, err := charges(RateStandingCharge, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return ProjectBill(ProjectionOptions{
+		From:            from,
+		To:              to,
+		At:              at,
+		Consumption:     recent,
+		History:         lastYear,
+		UnitRates:       unitRates,
+		StandingCharges: standingCharges,
+		Location:        loc,
+		Confidence:      options.Confidence,
+	})
+}
@@ -0,0 +1,105 @@
+package octopusenergy_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestHouseholdGetBillProjection(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	movedIn := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2021, 2, 15, 0, 0, 0, 0, time.UTC)
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111111", "21L0000001", intervalsBetween(at.AddDate(0, 0, -28), at, 0.5)...)
+	srv.AddTariffCharges("VAR-19-04-12", octopusenergy.FuelTypeElectricity, "E-1R-VAR-19-04-12-C", octopusenergy.RateStandardUnit,
+		octopusenergy.TariffCharge{ValueExcVat: 19, ValueIncVat: 20, ValidFrom: movedIn})
+	srv.AddTariffCharges("VAR-19-04-12", octopusenergy.FuelTypeElectricity, "E-1R-VAR-19-04-12-C", octopusenergy.RateStandingCharge,
+		octopusenergy.TariffCharge{ValueExcVat: 24, ValueIncVat: 25, ValidFrom: movedIn})
+
+	projection, err := srv.Client().Household.GetBillProjection(&octopusenergy.BillProjectionGetOptions{
+		MeterPoint: octopusenergy.HouseholdMeterPoint{
+			FuelType:           octopusenergy.FuelTypeElectricity,
+			MPN:                "1111111111111",
+			ActiveSerialNumber: "21L0000001",
+			Agreements:         []octopusenergy.Agreement{{TariffCode: "E-1R-VAR-19-04-12-C", ValidFrom: movedIn}},
+		},
+		At: &at,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !projection.From.Equal(time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)) || !projection.DataTo.Equal(at) {
+		t.Errorf("expected February to date, got %s to %s", projection.From, projection.DataTo)
+	}
+	if projection.ConsumptionToDate != 14*24 || projection.CostToDateIncVat != 14*24*20+14*25 {
+		t.Errorf("unexpected consumption to date %+v", projection)
+	}
+	// every day is the same, so the range is certain
+	if projection.Consumption != 28*24 || projection.ConsumptionLow != 28*24 || projection.ConsumptionHigh != 28*24 {
+		t.Errorf("expected %d kWh, got %+v", 28*24, projection)
+	}
+	if projection.CostIncVat != 28*24*20+28*25 || projection.CostExcVat != 28*24*19+28*24 || projection.CostHighIncVat != projection.CostIncVat {
+		t.Errorf("unexpected cost %+v", projection)
+	}
+	if projection.EstimatedRates {
+		t.Error("expected a variable tariff's rates to be known")
+	}
+}
+
+func TestProjectBill(t *testing.T) {
+	from := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	at := from.AddDate(0, 0, 10)
+
+	// usage varies from day to day
+	var consumption []octopusenergy.ConsumptionInterval
+	for day := 0; day < 10; day++ {
+		start := from.AddDate(0, 0, day)
+		consumption = append(consumption, intervalsBetween(start, start.AddDate(0, 0, 1), 0.3+0.1*float64(day%3))...)
+	}
+	// Agile rates are only published to the end of tomorrow
+	var rates []octopusenergy.TariffCharge
+	for t := from.AddDate(0, 0, -7); t.Before(at.AddDate(0, 0, 2)); t = t.Add(30 * time.Minute) {
+		price := 10.0
+		if t.Hour() >= 16 && t.Hour() < 19 {
+			price = 30
+		}
+		rates = append(rates, octopusenergy.TariffCharge{ValueExcVat: price, ValueIncVat: price, ValidFrom: t, ValidTo: t.Add(30 * time.Minute)})
+	}
+
+	projection, err := octopusenergy.ProjectBill(octopusenergy.ProjectionOptions{
+		From:        from,
+		To:          from.AddDate(0, 1, 0),
+		At:          at,
+		Consumption: consumption,
+		UnitRates:   rates,
+		Location:    time.UTC,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(projection.ConsumptionToDate-(4*14.4+3*19.2+3*24)) > 1e-9 {
+		t.Errorf("unexpected consumption to date %.2f", projection.ConsumptionToDate)
+	}
+	if !(projection.ConsumptionLow < projection.Consumption && projection.Consumption < projection.ConsumptionHigh) {
+		t.Errorf("expected a range around the projected consumption, got %+v", projection)
+	}
+	if !(projection.CostLowIncVat < projection.CostIncVat && projection.CostIncVat < projection.CostHighIncVat) {
+		t.Errorf("expected a range around the projected cost, got %+v", projection)
+	}
+	// the estimated rates repeat the published ones, so the price per kWh is unchanged
+	perKWh := projection.CostIncVat / projection.Consumption
+	if !projection.EstimatedRates || math.Abs(perKWh-(10*42+30*6)/48.0) > 1e-9 {
+		t.Errorf("expected estimated rates averaging %.2fp, got %.2fp", (10*42+30*6)/48.0, perKWh)
+	}
+
+	if _, err := octopusenergy.ProjectBill(octopusenergy.ProjectionOptions{From: from, To: from.AddDate(0, 1, 0), UnitRates: rates}); err == nil {
+		t.Error("expected an error without any consumption to project from")
+	}
+}
@@ -0,0 +1,201 @@
+package octopusenergy
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// TimeRange is a period of time from (inclusive) to (exclusive).
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Duration returns the length of the range.
+func (r TimeRange) Duration() time.Duration {
+	return r.To.Sub(r.From)
+}
+
+// QualityOptions is the options for CheckQuality.
+type QualityOptions struct {
+	// The period the intervals should cover. Defaults to the start of the first interval and the end
+	// of the last.
+	From time.Time
+	To   time.Time
+
+	// The expected length of an interval. Default is 30 minutes.
+	IntervalLength time.Duration
+
+	// The number of consecutive zero intervals reported as a zero run. Default is 6, three hours of
+	// half-hourly data, which is unusual for a household and often a communications failure.
+	MinZeroRun int
+
+	// Intervals above this are reported as out of range, zero checks for negative values only.
+	MaxConsumption float64
+}
+
+// QualityReport is the result of checking consumption data for problems.
+type QualityReport struct {
+	// The period checked.
+	Period TimeRange
+
+	// The percentage of the period covered by intervals.
+	Coverage float64
+
+	// Parts of the period with no interval, in order.
+	Gaps []TimeRange
+
+	// Intervals starting at the same time as an earlier one, which are not counted again.
+	Duplicates []ConsumptionInterval
+
+	// Runs of at least MinZeroRun consecutive intervals with zero consumption, in order.
+	ZeroRuns []TimeRange
+
+	// Intervals with negative consumption or above MaxConsumption.
+	OutOfRange []ConsumptionInterval
+
+	// Intervals not IntervalLength long, for example either side of a clock change.
+	Irregular []ConsumptionInterval
+}
+
+// OK reports whether no problems were found.
+func (r QualityReport) OK() bool {
+	return len(r.Gaps) == 0 && len(r.Duplicates) == 0 && len(r.ZeroRuns) == 0 && len(r.OutOfRange) == 0 && len(r.Irregular) == 0
+}
+
+// CheckQuality reports gaps, duplicates, zero runs, out of range values and irregular intervals in
+// consumption data. Intervals can be in any order, intervals starting outside the period are ignored.
+func CheckQuality(intervals []ConsumptionInterval, options QualityOptions) QualityReport {
+	if options.IntervalLength <= 0 {
+		options.IntervalLength = 30 * time.Minute
+	}
+	if options.MinZeroRun <= 0 {
+		options.MinZeroRun = 6
+	}
+
+	sorted := sortedIntervals(intervals)
+	report := QualityReport{Period: TimeRange{From: options.From, To: options.To}}
+	if len(sorted) > 0 {
+		if report.Period.From.IsZero() {
+			report.Period.From = sorted[0].Start
+		}
+		if report.Period.To.IsZero() {
+			report.Period.To = sorted[len(sorted)-1].End
+		}
+	}
+	if !report.Period.From.Before(report.Period.To) {
+		return report
+	}
+
+	var covered time.Duration
+	var zeros []ConsumptionInterval
+	at := report.Period.From
+	endZeroRun := func() {
+		if len(zeros) >= options.MinZeroRun {
+			report.ZeroRuns = append(report.ZeroRuns, TimeRange{From: zeros[0].Start, To: zeros[len(zeros)-1].End})
+		}
+		zeros = nil
+	}
+
+	// the previous interval within the period, those outside it are not reported on
+	var previous *ConsumptionInterval
+	for i, in := range sorted {
+		if in.Start.Before(report.Period.From) || !in.Start.Before(report.Period.To) {
+			continue
+		}
+		if previous != nil && in.Start.Equal(previous.Start) {
+			report.Duplicates = append(report.Duplicates, in)
+			continue
+		}
+		previous = &sorted[i]
+
+		if in.Start.After(at) {
+			report.Gaps = append(report.Gaps, TimeRange{From: at, To: in.Start})
+			endZeroRun()
+		}
+		if in.End.Sub(in.Start) != options.IntervalLength {
+			report.Irregular = append(report.Irregular, in)
+		}
+		if in.Consumption < 0 || (options.MaxConsumption > 0 && in.Consumption > options.MaxConsumption) {
+			report.OutOfRange = append(report.OutOfRange, in)
+		}
+		if in.Consumption == 0 {
+			zeros = append(zeros, in)
+		} else {
+			endZeroRun()
+		}
+
+		// overlapping intervals only count the part not already covered
+		end := in.End
+		if end.After(report.Period.To) {
+			end = report.Period.To
+		}
+		if end.After(at) {
+			if in.Start.After(at) {
+				covered += end.Sub(in.Start)
+			} else {
+				covered += end.Sub(at)
+			}
+			at = end
+		}
+	}
+	endZeroRun()
+
+	if at.Before(report.Period.To) {
+		report.Gaps = append(report.Gaps, TimeRange{From: at, To: report.Period.To})
+	}
+	report.Coverage = 100 * float64(covered) / float64(report.Period.Duration())
+	return report
+}
+
+// FillGaps requests consumption again for just the gaps given, such as those from CheckQuality, and
+// merges anything returned into intervals. The result is ordered by start time without duplicates.
+// The period in options is ignored.
+func (s *ConsumptionService) FillGaps(options *ConsumptionGetOptions, intervals []ConsumptionInterval, gaps []TimeRange) ([]ConsumptionInterval, error) {
+	return s.FillGapsWithContext(context.Background(), options, intervals, gaps)
+}
+
+// FillGapsWithContext same as FillGaps except it takes a Context.
+func (s *ConsumptionService) FillGapsWithContext(ctx context.Context, options *ConsumptionGetOptions, intervals []ConsumptionInterval, gaps []TimeRange) ([]ConsumptionInterval, error) {
+	merged := append([]ConsumptionInterval{}, intervals...)
+	for _, gap := range gaps {
+		res, err := s.GetPagesWithContext(ctx, &ConsumptionGetOptions{
+			MPN:          options.MPN,
+			SerialNumber: options.SerialNumber,
+			FuelType:     options.FuelType,
+			PeriodFrom:   Time(gap.From),
+			PeriodTo:     Time(gap.To),
+		})
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found, err := res.Intervals()
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, found...)
+	}
+
+	var out []ConsumptionInterval
+	for _, in := range sortedIntervals(merged) {
+		if n := len(out); n > 0 && in.Start.Equal(out[n-1].Start) {
+			continue
+		}
+		out = append(out, in)
+	}
+	return out, nil
+}
+
+// sortedIntervals returns a copy of intervals ordered by start time.
+func sortedIntervals(intervals []ConsumptionInterval) []ConsumptionInterval {
+	sorted := make([]ConsumptionInterval, len(intervals))
+	copy(sorted, intervals)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+	return sorted
+}
@@ -0,0 +1,91 @@
+package octopusenergy_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestCheckQuality(t *testing.T) {
+	day := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours float64) time.Time { return day.Add(time.Duration(hours * float64(time.Hour))) }
+
+	var intervals []octopusenergy.ConsumptionInterval
+	intervals = append(intervals, intervalsBetween(at(0), at(2), 0.4)...)
+	intervals = append(intervals, intervalsBetween(at(2), at(5), 0)...)
+	intervals = append(intervals, intervalsBetween(at(5), at(10), 0.4)...)
+	// 10:00 to 11:00 is missing, 11:00 to 12:00 is one long interval
+	intervals = append(intervals, octopusenergy.ConsumptionInterval{Start: at(11), End: at(12), Consumption: 0.8})
+	intervals = append(intervals, intervalsBetween(at(12), at(24), 0.4)...)
+	intervals[30].Consumption = -1
+	intervals = append(intervals, intervals[3])
+
+	report := octopusenergy.CheckQuality(intervals, octopusenergy.QualityOptions{
+		From: day,
+		To:   at(25),
+	})
+
+	if len(report.Gaps) != 2 || !report.Gaps[0].From.Equal(at(10)) || !report.Gaps[0].To.Equal(at(11)) || !report.Gaps[1].From.Equal(at(24)) {
+		t.Errorf("unexpected gaps %+v", report.Gaps)
+	}
+	if want := 100 * 23.0 / 25; math.Abs(report.Coverage-want) > 1e-9 {
+		t.Errorf("expected coverage %.2f, got %.2f", want, report.Coverage)
+	}
+	if len(report.Duplicates) != 1 || !report.Duplicates[0].Start.Equal(at(1.5)) {
+		t.Errorf("unexpected duplicates %+v", report.Duplicates)
+	}
+	if len(report.ZeroRuns) != 1 || !report.ZeroRuns[0].From.Equal(at(2)) || !report.ZeroRuns[0].To.Equal(at(5)) {
+		t.Errorf("unexpected zero runs %+v", report.ZeroRuns)
+	}
+	if len(report.OutOfRange) != 1 || report.OutOfRange[0].Consumption != -1 {
+		t.Errorf("unexpected out of range %+v", report.OutOfRange)
+	}
+	if len(report.Irregular) != 1 || !report.Irregular[0].Start.Equal(at(11)) {
+		t.Errorf("unexpected irregular intervals %+v", report.Irregular)
+	}
+	if report.OK() {
+		t.Error("expected problems to be reported")
+	}
+
+	// intervals before the period are not compared with those in it
+	for _, from := range []time.Time{at(1.5), at(2)} {
+		report := octopusenergy.CheckQuality(intervals, octopusenergy.QualityOptions{From: from, To: at(3)})
+		if want := from.Equal(at(1.5)); (len(report.Duplicates) == 1) != want || len(report.Duplicates) > 1 {
+			t.Errorf("from %s: unexpected duplicates %+v", from.Format("15:04"), report.Duplicates)
+		}
+	}
+}
+
+func TestConsumptionFillGaps(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	day := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111111", "1111111111", intervalsBetween(day, day.Add(24*time.Hour), 1)...)
+
+	// the first fetch missed two hours in the middle of the day
+	partial := append(intervalsBetween(day, day.Add(10*time.Hour), 1), intervalsBetween(day.Add(12*time.Hour), day.Add(24*time.Hour), 1)...)
+	report := octopusenergy.CheckQuality(partial, octopusenergy.QualityOptions{})
+	if len(report.Gaps) != 1 {
+		t.Fatalf("expected one gap, got %+v", report.Gaps)
+	}
+
+	filled, err := srv.Client().Consumption.FillGaps(&octopusenergy.ConsumptionGetOptions{
+		MPN:          "1111111111111",
+		SerialNumber: "1111111111",
+		FuelType:     octopusenergy.FuelTypeElectricity,
+	}, partial, report.Gaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report := octopusenergy.CheckQuality(filled, octopusenergy.QualityOptions{}); !report.OK() || report.Coverage != 100 {
+		t.Errorf("expected the gap filled, got %+v", report)
+	}
+	if len(filled) != 48 {
+		t.Errorf("expected 48 intervals, got %d", len(filled))
+	}
+}
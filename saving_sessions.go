@@ -0,0 +1,290 @@
+package octopusenergy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// the scheme compares against this many previous matching days
+	savingSessionWeekdayBaselineDays = 10
+	savingSessionWeekendBaselineDays = 4
+
+	// stop looking for matching days after this far back
+	savingSessionBaselineSearchDays = 60
+)
+
+// ErrInsufficientHistory is returned when there is not enough consumption data for a calculation.
+var ErrInsufficientHistory = errors.New("not enough consumption history")
+
+// SavingSessionService handles communication with the Saving Sessions related parts of the GraphQL API.
+type SavingSessionService service
+
+// SavingSessionsGetOptions is the options for Get.
+type SavingSessionsGetOptions struct {
+	// The octopus account number to retrieve enrolment details for.
+	AccountNumber string
+}
+
+// SavingSessionsGetOutput is the returned struct from Get.
+type SavingSessionsGetOutput struct {
+	// Whether the account has signed up to the scheme at all.
+	HasJoinedCampaign bool
+
+	// Every event in the campaign, with the account's participation.
+	Events []SavingSessionEvent
+}
+
+// SavingSessionEvent is a single demand flexibility event.
+type SavingSessionEvent struct {
+	ID   int
+	Code string
+
+	StartAt time.Time
+	EndAt   time.Time
+
+	// Octopoints paid per kWh saved against the baseline.
+	RewardPerKwhInOctoPoints int
+
+	// Whether the account has joined this event.
+	Joined bool
+
+	// Octopoints awarded to the account, zero until the event has been settled.
+	RewardGivenInOctoPoints int
+}
+
+const savingSessionsQuery = `query SavingSessions($accountNumber: String!) {
+  savingSessions {
+    events {
+      id
+      code
+      startAt
+      endAt
+      rewardPerKwhInOctoPoints
+    }
+    account(accountNumber: $accountNumber) {
+      hasJoinedCampaign
+      joinedEvents {
+        eventId
+        rewardGivenInOctoPoints
+      }
+    }
+  }
+}`
+
+// Get retrieves the saving session events and which of them the account has joined.
+func (s *SavingSessionService) Get(options *SavingSessionsGetOptions) (*SavingSessionsGetOutput, error) {
+	return s.GetWithContext(context.Background(), options)
+}
+
+// GetWithContext same as Get except it takes a Context.
+func (s *SavingSessionService) GetWithContext(ctx context.Context, options *SavingSessionsGetOptions) (*SavingSessionsGetOutput, error) {
+	var res struct {
+		SavingSessions struct {
+			Events []struct {
+				ID                       int    `json:"id"`
+				Code                     string `json:"code"`
+				StartAt                  string `json:"startAt"`
+				EndAt                    string `json:"endAt"`
+				RewardPerKwhInOctoPoints int    `json:"rewardPerKwhInOctoPoints"`
+			} `json:"events"`
+			Account struct {
+				HasJoinedCampaign bool `json:"hasJoinedCampaign"`
+				JoinedEvents      []struct {
+					EventID                 int `json:"eventId"`
+					RewardGivenInOctoPoints int `json:"rewardGivenInOctoPoints"`
+				} `json:"joinedEvents"`
+			} `json:"account"`
+		} `json:"savingSessions"`
+	}
+
+	vars := map[string]interface{}{"accountNumber": options.AccountNumber}
+	if err := s.client.GraphQL.QueryWithContext(ctx, savingSessionsQuery, vars, &res); err != nil {
+		return nil, err
+	}
+
+	joined := map[int]int{}
+	for _, e := range res.SavingSessions.Account.JoinedEvents {
+		joined[e.EventID] = e.RewardGivenInOctoPoints
+	}
+
+	out := SavingSessionsGetOutput{HasJoinedCampaign: res.SavingSessions.Account.HasJoinedCampaign}
+	for _, e := range res.SavingSessions.Events {
+		start, err := parseKrakenTime(e.StartAt)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseKrakenTime(e.EndAt)
+		if err != nil {
+			return nil, err
+		}
+		reward, ok := joined[e.ID]
+		out.Events = append(out.Events, SavingSessionEvent{
+			ID:                       e.ID,
+			Code:                     e.Code,
+			StartAt:                  start,
+			EndAt:                    end,
+			RewardPerKwhInOctoPoints: e.RewardPerKwhInOctoPoints,
+			Joined:                   ok,
+			RewardGivenInOctoPoints:  reward,
+		})
+	}
+
+	return &out, nil
+}
+
+// SavingSessionJoinOptions is the options for Join.
+type SavingSessionJoinOptions struct {
+	// The octopus account number joining the event.
+	AccountNumber string
+
+	// The code of the event to join, see SavingSessionEvent.Code.
+	EventCode string
+}
+
+const joinSavingSessionMutation = `mutation JoinSavingSession($input: JoinSavingSessionsEventInput!) {
+  joinSavingSessionsEvent(input: $input) {
+    possibleErrors {
+      message
+    }
+  }
+}`
+
+// Join signs the account up to an upcoming event.
+func (s *SavingSessionService) Join(options *SavingSessionJoinOptions) error {
+	return s.JoinWithContext(context.Background(), options)
+}
+
+// JoinWithContext same as Join except it takes a Context.
+func (s *SavingSessionService) JoinWithContext(ctx context.Context, options *SavingSessionJoinOptions) error {
+	var res struct {
+		JoinSavingSessionsEvent struct {
+			PossibleErrors []struct {
+				Message string `json:"message"`
+			} `json:"possibleErrors"`
+		} `json:"joinSavingSessionsEvent"`
+	}
+
+	vars := map[string]interface{}{
+		"input": map[string]interface{}{
+			"accountNumber": options.AccountNumber,
+			"eventCode":     options.EventCode,
+		},
+	}
+	if err := s.client.GraphQL.QueryWithContext(ctx, joinSavingSessionMutation, vars, &res); err != nil {
+		return err
+	}
+
+	if errs := res.JoinSavingSessionsEvent.PossibleErrors; len(errs) > 0 {
+		return fmt.Errorf("failed to join saving session %s: %s", options.EventCode, errs[0].Message)
+	}
+	return nil
+}
+
+// SavingSessionPerformance is how a household did in an event compared to its baseline.
+type SavingSessionPerformance struct {
+	Event SavingSessionEvent
+
+	// The days the baseline was averaged over.
+	BaselineDays []time.Time
+
+	// Totals over the event window, in kWh.
+	Baseline float64
+	Actual   float64
+
+	// Baseline minus actual, negative if the household used more than usual.
+	Saved float64
+
+	// Saved multiplied by the event reward, zero if nothing was saved.
+	EstimatedOctoPoints int
+
+	// Per half hour breakdown of the event.
+	Slots []SavingSessionSlot
+}
+
+// SavingSessionSlot is a single half hour of an event.
+type SavingSessionSlot struct {
+	Start    time.Time
+	Baseline float64
+	Actual   float64
+}
+
+// SavingSessionPerformanceFor calculates the scheme's baseline for an event from historic half hourly
+// consumption and compares it with what was actually used. The baseline for each half hour is the mean
+// of the same half hour over the previous 10 weekdays, or 4 weekend days for a weekend event, skipping
+// days that had an event of their own. history must cover those days and the event itself, other
+// events can be passed to be excluded from the baseline.
+func SavingSessionPerformanceFor(event SavingSessionEvent, history []ConsumptionInterval, otherEvents []SavingSessionEvent) (*SavingSessionPerformance, error) {
+	loc := UKLocation()
+
+	byStart := make(map[time.Time]float64, len(history))
+	for _, in := range history {
+		byStart[in.Start.UTC()] = in.Consumption
+	}
+
+	eventDays := map[string]bool{}
+	for _, e := range otherEvents {
+		eventDays[e.StartAt.In(loc).Format("2006-01-02")] = true
+	}
+
+	start := event.StartAt.In(loc)
+	weekend := isWeekend(start)
+	needed := savingSessionWeekdayBaselineDays
+	if weekend {
+		needed = savingSessionWeekendBaselineDays
+	}
+
+	// find the previous matching days which have data for the event window
+	var days []time.Time
+	for back := 1; back <= savingSessionBaselineSearchDays && len(days) < needed; back++ {
+		day := start.AddDate(0, 0, -back)
+		if isWeekend(day) != weekend || eventDays[day.Format("2006-01-02")] {
+			continue
+		}
+		if _, ok := byStart[day.UTC()]; !ok {
+			continue
+		}
+		days = append(days, day)
+	}
+	if len(days) == 0 {
+		return nil, ErrInsufficientHistory
+	}
+
+	perf := SavingSessionPerformance{Event: event, BaselineDays: days}
+
+	for slot := start; slot.Before(event.EndAt); slot = slot.Add(30 * time.Minute) {
+		offset := slot.Sub(start)
+
+		var sum float64
+		var n int
+		for _, day := range days {
+			if v, ok := byStart[day.Add(offset).UTC()]; ok {
+				sum += v
+				n++
+			}
+		}
+
+		s := SavingSessionSlot{Start: slot, Actual: byStart[slot.UTC()]}
+		if n > 0 {
+			s.Baseline = sum / float64(n)
+		}
+
+		perf.Slots = append(perf.Slots, s)
+		perf.Baseline += s.Baseline
+		perf.Actual += s.Actual
+	}
+
+	perf.Saved = perf.Baseline - perf.Actual
+	if perf.Saved > 0 {
+		perf.EstimatedOctoPoints = int(math.Round(perf.Saved * float64(event.RewardPerKwhInOctoPoints)))
+	}
+
+	return &perf, nil
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
@@ -0,0 +1,68 @@
+package octopusenergy_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+func TestSavingSessionPerformanceFor(t *testing.T) {
+	loc := octopusenergy.UKLocation()
+
+	// Tuesday 17:00 - 18:00
+	event := octopusenergy.SavingSessionEvent{
+		Code:                     "EVENT_1",
+		StartAt:                  time.Date(2022, 11, 15, 17, 0, 0, 0, loc),
+		EndAt:                    time.Date(2022, 11, 15, 18, 0, 0, 0, loc),
+		RewardPerKwhInOctoPoints: 1800,
+	}
+	// an earlier event day is skipped when building the baseline
+	other := octopusenergy.SavingSessionEvent{
+		StartAt: time.Date(2022, 11, 10, 17, 0, 0, 0, loc),
+		EndAt:   time.Date(2022, 11, 10, 18, 0, 0, 0, loc),
+	}
+
+	var history []octopusenergy.ConsumptionInterval
+	for day := time.Date(2022, 10, 1, 0, 0, 0, 0, loc); day.Before(time.Date(2022, 11, 16, 0, 0, 0, 0, loc)); day = day.AddDate(0, 0, 1) {
+		for slot := 0; slot < 48; slot++ {
+			start := day.Add(time.Duration(slot) * 30 * time.Minute)
+			usage := 0.5
+			switch {
+			case day.Day() == 15 && day.Month() == time.November:
+				usage = 0.2
+			case day.Day() == 10 && day.Month() == time.November:
+				usage = 5 // would skew the baseline if not excluded
+			}
+			history = append(history, octopusenergy.ConsumptionInterval{Start: start, End: start.Add(30 * time.Minute), Consumption: usage})
+		}
+	}
+
+	perf, err := octopusenergy.SavingSessionPerformanceFor(event, history, []octopusenergy.SavingSessionEvent{other})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(perf.BaselineDays) != 10 {
+		t.Errorf("expected 10 baseline days, got %d", len(perf.BaselineDays))
+	}
+	for _, d := range perf.BaselineDays {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || d.Day() == 10 {
+			t.Errorf("unexpected baseline day %s", d.Format("Mon 2 Jan"))
+		}
+	}
+	if len(perf.Slots) != 2 {
+		t.Fatalf("expected 2 slots, got %d", len(perf.Slots))
+	}
+	if math.Abs(perf.Baseline-1.0) > 1e-9 || math.Abs(perf.Actual-0.4) > 1e-9 {
+		t.Errorf("unexpected baseline %v and actual %v", perf.Baseline, perf.Actual)
+	}
+	if perf.EstimatedOctoPoints != 1080 {
+		t.Errorf("expected 1080 points, got %d", perf.EstimatedOctoPoints)
+	}
+
+	if _, err := octopusenergy.SavingSessionPerformanceFor(event, nil, nil); err != octopusenergy.ErrInsufficientHistory {
+		t.Errorf("expected ErrInsufficientHistory, got %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+// Appliance is a flexible load that can run whenever electricity is cheapest.
+type Appliance struct {
+	// Name of the appliance, used in the plan.
+	Name string
+
+	// Power drawn while running, in kW.
+	Power float64
+
+	// How long the appliance needs to run for.
+	Duration time.Duration
+
+	// Whether the run can be split across separate half hours, as an immersion heater or an electric
+	// vehicle can. A washing machine or dishwasher must run without a break.
+	Interruptible bool
+
+	// The appliance must not start before this time. Optional.
+	NotBefore time.Time
+
+	// The appliance must finish by this time. Optional, default is the end of the rates.
+	Deadline time.Time
+}
+
+// LoadOptions is the options for PlanLoads.
+type LoadOptions struct {
+	Appliances []Appliance
+
+	// The unit rates to plan over, in any order.
+	Rates []octopusenergy.TariffCharge
+
+	// When the plan starts, rounded up to the next half hour. Default is now.
+	From time.Time
+
+	// The most power all the appliances can draw at once, in kW, so the main fuse is not overloaded.
+	// Zero for no limit.
+	MaxPower float64
+}
+
+// ApplianceRun is a period an appliance runs without a break.
+type ApplianceRun struct {
+	Appliance string
+	From      time.Time
+	To        time.Time
+
+	// Energy used, in kWh, and its cost in pence.
+	Energy float64
+	Cost   float64
+}
+
+// LoadSlot is a half hour of a plan and the appliances running in it.
+type LoadSlot struct {
+	From time.Time
+	To   time.Time
+
+	// The unit rate in pence per kWh including VAT.
+	Price float64
+
+	// The power drawn by the appliances running, in kW.
+	Power float64
+
+	Appliances []string
+}
+
+// LoadPlan is a plan for running several appliances.
+type LoadPlan struct {
+	// When each appliance runs, ordered by start time.
+	Runs []ApplianceRun
+
+	// Every half hour of the plan, including those where nothing runs.
+	Slots []LoadSlot
+
+	// Total energy used in kWh and its cost in pence.
+	Energy float64
+	Cost   float64
+
+	// The appliances that could not be fitted in their time window within the power limit.
+	Unscheduled []string
+}
+
+// PlanLoads plans when to run each appliance so their combined cost is lowest, within each
+// appliance's time window, without breaking runs that can not be interrupted and without drawing
+// more than MaxPower at once. The most constrained appliances are placed first, then each is moved
+// again while that lowers the cost.
+func PlanLoads(options LoadOptions) (*LoadPlan, error) {
+	if options.From.IsZero() {
+		options.From = time.Now()
+	}
+	from := options.From.Truncate(slotLength)
+	if from.Before(options.From) {
+		from = from.Add(slotLength)
+	}
+
+	end := from
+	for _, r := range options.Rates {
+		if r.ValidTo.After(end) {
+			end = r.ValidTo
+		}
+	}
+	for _, a := range options.Appliances {
+		if a.Power <= 0 || a.Duration <= 0 {
+			return nil, fmt.Errorf("appliance %q needs a power and duration above zero", a.Name)
+		}
+		if a.Deadline.After(end) {
+			end = a.Deadline
+		}
+	}
+	slots := priceSlots(octopusenergy.NewChargeIndex(options.Rates), from, end)
+	if len(slots) == 0 {
+		return nil, errors.New("no rates to plan over")
+	}
+
+	p := loadPlanner{slots: slots, maxPower: options.MaxPower, load: make([]float64, len(slots))}
+	order := make([]int, len(options.Appliances))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := options.Appliances[order[i]], options.Appliances[order[j]]
+		if a.Interruptible != b.Interruptible {
+			return !a.Interruptible
+		}
+		return p.flexibility(a) < p.flexibility(b)
+	})
+
+	placed := make([][]int, len(options.Appliances))
+	for _, i := range order {
+		placed[i] = p.place(options.Appliances[i])
+	}
+	// moving an appliance once the others are placed can only lower the cost, so repeat until stable
+	for pass := 0; pass < 3; pass++ {
+		moved := false
+		for _, i := range order {
+			a := options.Appliances[i]
+			p.remove(a, placed[i])
+			best := p.place(a)
+			if p.cost(a, best) < p.cost(a, placed[i])-1e-9 || placed[i] == nil && best != nil {
+				placed[i], moved = best, true
+			} else {
+				p.remove(a, best)
+				p.add(a, placed[i])
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+
+	return p.plan(options.Appliances, placed), nil
+}
+
+type loadPlanner struct {
+	slots    []Slot
+	maxPower float64
+	load     []float64
+}
+
+// window returns the range of slots an appliance may run in.
+func (p *loadPlanner) window(a Appliance) (int, int) {
+	lo, hi := 0, len(p.slots)
+	for i, s := range p.slots {
+		if s.From.Before(a.NotBefore) {
+			lo = i + 1
+		}
+		if !a.Deadline.IsZero() && s.To.After(a.Deadline) && hi == len(p.slots) {
+			hi = i
+		}
+	}
+	return lo, hi
+}
+
+// needed returns the number of half hours an appliance runs in.
+func needed(a Appliance) int {
+	return int(math.Ceil(float64(a.Duration) / float64(slotLength)))
+}
+
+// flexibility is the number of half hours an appliance could run in beyond those it needs.
+func (p *loadPlanner) flexibility(a Appliance) int {
+	lo, hi := p.window(a)
+	return hi - lo - needed(a)
+}
+
+func (p *loadPlanner) fits(a Appliance, i int) bool {
+	return p.maxPower <= 0 || p.load[i]+a.Power <= p.maxPower+1e-9
+}
+
+// place finds the cheapest slots for an appliance and adds its load to them, nil if it does not fit.
+func (p *loadPlanner) place(a Appliance) []int {
+	lo, hi := p.window(a)
+	n := needed(a)
+
+	var best []int
+	if a.Interruptible {
+		var free []int
+		for i := lo; i < hi; i++ {
+			if p.fits(a, i) {
+				free = append(free, i)
+			}
+		}
+		if len(free) < n {
+			return nil
+		}
+		sort.SliceStable(free, func(i, j int) bool {
+			return p.slots[free[i]].Price < p.slots[free[j]].Price
+		})
+		best = free[:n]
+		sort.Ints(best)
+	} else {
+		bestCost := math.Inf(1)
+		for start := lo; start+n <= hi; start++ {
+			run := make([]int, 0, n)
+			for i := start; i < start+n && p.fits(a, i); i++ {
+				run = append(run, i)
+			}
+			if len(run) < n {
+				continue
+			}
+			if c := p.cost(a, run); c < bestCost-1e-9 {
+				best, bestCost = run, c
+			}
+		}
+	}
+
+	p.add(a, best)
+	return best
+}
+
+func (p *loadPlanner) add(a Appliance, slots []int) {
+	for _, i := range slots {
+		p.load[i] += a.Power
+	}
+}
+
+func (p *loadPlanner) remove(a Appliance, slots []int) {
+	for _, i := range slots {
+		p.load[i] -= a.Power
+	}
+}
+
+// energy returns the energy an appliance uses in each of its slots, a partial last half hour
+// being put in the most expensive slot, or the last for a run that can not be interrupted.
+func (p *loadPlanner) energy(a Appliance, slots []int) []float64 {
+	energy := make([]float64, len(slots))
+	if len(slots) == 0 {
+		return energy
+	}
+	partial := len(slots) - 1
+	if a.Interruptible {
+		for k, i := range slots {
+			if p.slots[i].Price > p.slots[slots[partial]].Price {
+				partial = k
+			}
+		}
+	}
+	half := slotLength.Hours()
+	for k := range slots {
+		energy[k] = a.Power * half
+	}
+	energy[partial] = a.Power * (a.Duration.Hours() - half*float64(len(slots)-1))
+	return energy
+}
+
+func (p *loadPlanner) cost(a Appliance, slots []int) float64 {
+	if slots == nil {
+		return math.Inf(1)
+	}
+	var cost float64
+	for k, e := range p.energy(a, slots) {
+		cost += e * p.slots[slots[k]].Price
+	}
+	return cost
+}
+
+func (p *loadPlanner) plan(appliances []Appliance, placed [][]int) *LoadPlan {
+	plan := LoadPlan{}
+	for _, s := range p.slots {
+		plan.Slots = append(plan.Slots, LoadSlot{From: s.From, To: s.To, Price: s.Price})
+	}
+
+	for n, a := range appliances {
+		if placed[n] == nil {
+			plan.Unscheduled = append(plan.Unscheduled, a.Name)
+			continue
+		}
+		for k, e := range p.energy(a, placed[n]) {
+			i := placed[n][k]
+			slot := &plan.Slots[i]
+			slot.Power += a.Power
+			slot.Appliances = append(slot.Appliances, a.Name)
+			plan.Energy += e
+			plan.Cost += e * slot.Price
+
+			if r := len(plan.Runs) - 1; k > 0 && placed[n][k-1] == i-1 {
+				plan.Runs[r].To = slot.To
+				plan.Runs[r].Energy += e
+				plan.Runs[r].Cost += e * slot.Price
+				continue
+			}
+			plan.Runs = append(plan.Runs, ApplianceRun{Appliance: a.Name, From: slot.From, To: slot.To, Energy: e, Cost: e * slot.Price})
+		}
+		if !a.Interruptible {
+			// a run that can not be interrupted finishes part way through its last half hour
+			plan.Runs[len(plan.Runs)-1].To = plan.Runs[len(plan.Runs)-1].From.Add(a.Duration)
+		}
+	}
+
+	sort.SliceStable(plan.Runs, func(i, j int) bool {
+		return plan.Runs[i].From.Before(plan.Runs[j].From)
+	})
+	return &plan
+}
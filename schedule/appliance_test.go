@@ -0,0 +1,51 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanLoads(t *testing.T) {
+	plan, err := PlanLoads(LoadOptions{
+		Appliances: []Appliance{
+			{Name: "washing machine", Power: 2, Duration: 2 * time.Hour},
+			{Name: "dishwasher", Power: 1.5, Duration: time.Hour, Deadline: midnight.Add(2 * time.Hour)},
+			{Name: "immersion heater", Power: 3, Duration: 75 * time.Minute, Interruptible: true},
+			{Name: "electric vehicle", Power: 7, Duration: time.Hour, Interruptible: true},
+		},
+		Rates:    halfHourly(midnight, 20, 10, 5, 5, 10, 30, 2, 2),
+		From:     midnight.Add(-10 * time.Minute),
+		MaxPower: 3.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(slots int) time.Time { return midnight.Add(time.Duration(slots) * slotLength) }
+	want := []ApplianceRun{
+		{Appliance: "immersion heater", From: at(0), To: at(1), Energy: 0.75},
+		{Appliance: "washing machine", From: at(1), To: at(5), Energy: 4},
+		{Appliance: "dishwasher", From: at(2), To: at(4), Energy: 1.5},
+		{Appliance: "immersion heater", From: at(6), To: at(8), Energy: 3},
+	}
+	if len(plan.Runs) != len(want) {
+		t.Fatalf("expected %d runs, got %+v", len(want), plan.Runs)
+	}
+	for i, r := range plan.Runs {
+		if r.Appliance != want[i].Appliance || !r.From.Equal(want[i].From) || !r.To.Equal(want[i].To) || !near(r.Energy, want[i].Energy) {
+			t.Errorf("run %d: expected %+v, got %+v", i, want[i], r)
+		}
+	}
+
+	if len(plan.Unscheduled) != 1 || plan.Unscheduled[0] != "electric vehicle" {
+		t.Errorf("expected the vehicle not to fit under the power limit, got %v", plan.Unscheduled)
+	}
+	if !near(plan.Cost, 30+7.5+21) || !near(plan.Energy, 9.25) {
+		t.Errorf("unexpected cost %.2fp for %.2f kWh", plan.Cost, plan.Energy)
+	}
+	for _, s := range plan.Slots {
+		if s.Power > 3.5 {
+			t.Errorf("power limit exceeded at %s: %.1f kW from %v", s.From, s.Power, s.Appliances)
+		}
+	}
+}
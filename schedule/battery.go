@@ -0,0 +1,236 @@
+// Package schedule plans when to use electricity on tariffs whose unit rates change through the
+// day, such as Agile, for example when to charge a home battery or an electric vehicle.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+const slotLength = 30 * time.Minute
+
+// Battery describes a home battery or an electric vehicle.
+type Battery struct {
+	// Usable capacity in kWh.
+	Capacity float64
+
+	// Maximum charge power in kW.
+	ChargePower float64
+
+	// Maximum discharge power in kW, zero if it can not discharge.
+	DischargePower float64
+
+	// The current state of charge in kWh.
+	StateOfCharge float64
+
+	// The battery is never discharged below this state of charge in kWh.
+	MinStateOfCharge float64
+
+	// The fraction of the energy drawn from the grid that is stored, between 0 and 1. Default is 1.
+	Efficiency float64
+}
+
+// ChargeOptions is the options for PlanCharge.
+type ChargeOptions struct {
+	Battery Battery
+
+	// The unit rates to charge at, in any order, for example Agile standard unit rates from
+	// TariffChargeService.
+	Rates []octopusenergy.TariffCharge
+
+	// Whether the battery may discharge, to power the home or export, when that saves money.
+	Discharge bool
+
+	// What discharged energy is worth, for example Agile Outgoing rates. Default is Rates, the
+	// battery powering the home instead of importing.
+	ExportRates []octopusenergy.TariffCharge
+
+	// When the schedule starts. Default is now.
+	From time.Time
+
+	// The time the battery must reach Target by.
+	ReadyBy time.Time
+
+	// The state of charge needed by ReadyBy in kWh. Default is full.
+	Target float64
+
+	// The state of charge is planned in steps of this many kWh. Default is 0.1.
+	Resolution float64
+}
+
+// Slot is a period of the schedule and what the battery does in it.
+type Slot struct {
+	From time.Time
+	To   time.Time
+
+	// The unit rate in pence per kWh including VAT.
+	Price float64
+
+	// Energy drawn from the grid in kWh, negative when discharging.
+	Energy float64
+
+	// The state of charge at the end of the slot in kWh.
+	StateOfCharge float64
+
+	// The cost of the slot in pence, negative when discharging earns or saves money.
+	Cost float64
+}
+
+// Schedule is a charge plan.
+type Schedule struct {
+	// Every slot from the start of the schedule, including those where the battery is idle.
+	Slots []Slot
+
+	// Total energy drawn from the grid in kWh, less any discharged.
+	Energy float64
+
+	// Total cost in pence.
+	Cost float64
+
+	// The state of charge at the end of the schedule in kWh.
+	StateOfCharge float64
+
+	// How far short of Target the battery will be in kWh, when it can not charge enough in time.
+	Shortfall float64
+
+	// False when rates were not published up to ReadyBy. The schedule then ends with the last rate,
+	// leaving the rest of the charge for the time after at full power, and should be planned again
+	// when more rates are published.
+	Complete bool
+}
+
+// Charging returns the slots in which the battery charges.
+func (s *Schedule) Charging() []Slot {
+	var out []Slot
+	for _, slot := range s.Slots {
+		if slot.Energy > 0 {
+			out = append(out, slot)
+		}
+	}
+	return out
+}
+
+// StateOfChargeAt returns the state of charge the schedule expects at t.
+func (s *Schedule) StateOfChargeAt(t time.Time, initial float64) float64 {
+	soc := initial
+	for _, slot := range s.Slots {
+		if slot.To.After(t) {
+			break
+		}
+		soc = slot.StateOfCharge
+	}
+	return soc
+}
+
+// PlanCharge finds the cheapest way to reach the target state of charge by the deadline, charging
+// in the cheapest half hours and, if allowed, discharging when energy is worth more than it costs
+// to replace. Negative rates are used to charge beyond the target.
+func PlanCharge(options ChargeOptions) (*Schedule, error) {
+	b := options.Battery
+	if b.Capacity <= 0 || b.ChargePower <= 0 {
+		return nil, errors.New("battery capacity and charge power must be above zero")
+	}
+	if b.Efficiency <= 0 || b.Efficiency > 1 {
+		b.Efficiency = 1
+	}
+	if options.Resolution <= 0 {
+		options.Resolution = 0.1
+	}
+	if options.Target <= 0 || options.Target > b.Capacity {
+		options.Target = b.Capacity
+	}
+	if options.From.IsZero() {
+		options.From = time.Now()
+	}
+	if !options.From.Before(options.ReadyBy) {
+		return nil, errors.New("ready by must be after the start of the schedule")
+	}
+
+	rates := octopusenergy.NewChargeIndex(options.Rates)
+	exportRates := rates
+	if options.ExportRates != nil {
+		exportRates = octopusenergy.NewChargeIndex(options.ExportRates)
+	}
+	slots := priceSlots(rates, options.From, options.ReadyBy)
+	if len(slots) == 0 {
+		return nil, fmt.Errorf("no rates from %s", options.From.Format(time.RFC3339))
+	}
+
+	schedule := Schedule{Complete: !slots[len(slots)-1].To.Before(options.ReadyBy)}
+	needed := options.Target
+	if !schedule.Complete {
+		// leave what can be charged at full power after the last published rate
+		after := options.ReadyBy.Sub(slots[len(slots)-1].To).Hours() * b.ChargePower * b.Efficiency
+		needed = math.Max(needed-after, 0)
+	}
+
+	steps := make([]step, len(slots))
+	for n, slot := range slots {
+		exportPrice, canExport := exportRates.At(slot.From)
+		steps[n] = step{
+			hours:       slot.To.Sub(slot.From).Hours(),
+			importPrice: slot.Price,
+			exportPrice: exportPrice.ValueIncVat,
+			discharge:   options.Discharge && canExport,
+		}
+	}
+
+	res := options.Resolution
+	path, reached := optimise(steps, b, res, int(math.Ceil(needed/res-1e-9)))
+	end := path[len(path)-1]
+	if !reached {
+		schedule.Shortfall = needed - float64(end)*res
+	}
+
+	for n, slot := range slots {
+		slot.Energy, slot.Cost = steps[n].flow(float64(path[n+1]-path[n])*res, b.Efficiency)
+		slot.StateOfCharge = float64(path[n+1]) * res
+		schedule.Slots = append(schedule.Slots, slot)
+		schedule.Energy += slot.Energy
+		schedule.Cost += slot.Cost
+	}
+	schedule.StateOfCharge = float64(end) * res
+	return &schedule, nil
+}
+
+// priceSlots splits from to to into half hours, priced at the rate in force at their start, until
+// the first half hour without a rate. The first slot is shorter if from is part way through one.
+func priceSlots(rates octopusenergy.ChargeIndex, from, to time.Time) []Slot {
+	var slots []Slot
+	for at := from; at.Before(to); {
+		end := at.Truncate(slotLength).Add(slotLength)
+		if end.After(to) {
+			end = to
+		}
+		rate, ok := rates.At(at)
+		if !ok {
+			break
+		}
+		slots = append(slots, Slot{From: at, To: end, Price: rate.ValueIncVat})
+		at = end
+	}
+	return slots
+}
+
+// gridEnergy returns the energy drawn from the grid to store the given energy, negative energy
+// being discharged.
+func gridEnergy(stored, efficiency float64) float64 {
+	if stored > 0 {
+		return stored / efficiency
+	}
+	return stored
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
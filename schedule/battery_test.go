@@ -0,0 +1,142 @@
+package schedule
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+var midnight = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// halfHourly returns half-hourly rates from from, one for each price.
+func halfHourly(from time.Time, prices ...float64) []octopusenergy.TariffCharge {
+	rates := make([]octopusenergy.TariffCharge, len(prices))
+	for i, p := range prices {
+		at := from.Add(time.Duration(i) * slotLength)
+		rates[i] = octopusenergy.TariffCharge{ValueExcVat: p / 1.05, ValueIncVat: p, ValidFrom: at, ValidTo: at.Add(slotLength)}
+	}
+	return rates
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestPlanCharge(t *testing.T) {
+	schedule, err := PlanCharge(ChargeOptions{
+		Battery: Battery{Capacity: 10, ChargePower: 3, StateOfCharge: 2},
+		Rates:   halfHourly(midnight, 30, 10, 20, 5, 25, 15, -2, 40),
+		From:    midnight,
+		ReadyBy: midnight.Add(4 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float64{0, 1.5, 1.5, 1.5, 0.5, 1.5, 1.5, 0}
+	for i, slot := range schedule.Slots {
+		if !near(slot.Energy, want[i]) {
+			t.Errorf("slot %d at %.0fp: expected %.1f kWh, got %.2f", i, slot.Price, want[i], slot.Energy)
+		}
+	}
+	if !near(schedule.Cost, 1.5*(-2+5+10+15+20)+0.5*25) || !near(schedule.StateOfCharge, 10) || !schedule.Complete {
+		t.Errorf("unexpected schedule %+v", schedule)
+	}
+	if len(schedule.Charging()) != 6 {
+		t.Errorf("expected 6 charging slots, got %d", len(schedule.Charging()))
+	}
+}
+
+func TestPlanChargeDischarge(t *testing.T) {
+	options := ChargeOptions{
+		Battery: Battery{Capacity: 10, ChargePower: 3, DischargePower: 3, StateOfCharge: 5},
+		Rates:   halfHourly(midnight, 5, 5, 40, 40),
+		From:    midnight,
+		ReadyBy: midnight.Add(2 * time.Hour),
+		Target:  5,
+	}
+
+	schedule, err := PlanCharge(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(schedule.Cost, 0) {
+		t.Errorf("expected nothing to do without discharging, got %+v", schedule)
+	}
+
+	options.Discharge = true
+	if schedule, err = PlanCharge(options); err != nil {
+		t.Fatal(err)
+	}
+	if !near(schedule.Cost, 3*5-3*40) || !near(schedule.Slots[3].Energy, -1.5) || !near(schedule.StateOfCharge, 5) {
+		t.Errorf("expected to charge cheaply and discharge when dear, got %+v", schedule)
+	}
+}
+
+func TestPlanChargeIncomplete(t *testing.T) {
+	schedule, err := PlanCharge(ChargeOptions{
+		Battery: Battery{Capacity: 60, ChargePower: 7, StateOfCharge: 30, Efficiency: 0.9},
+		Rates:   halfHourly(midnight, 20, 15, 10, 12),
+		From:    midnight.Add(10 * time.Minute),
+		ReadyBy: midnight.Add(6 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if schedule.Complete || len(schedule.Slots) != 4 || !schedule.Slots[0].From.Equal(midnight.Add(10*time.Minute)) {
+		t.Fatalf("expected a partial schedule until the last rate, got %+v", schedule)
+	}
+	// 4 hours at 6.3 kW stored leaves 4.8 kWh to store now, up to 3.1 kWh a slot
+	if !near(schedule.Slots[2].Energy, 3.1/0.9) || !near(schedule.Slots[3].Energy, 1.7/0.9) || !near(schedule.StateOfCharge, 34.8) {
+		t.Errorf("expected the two cheapest slots used, got %+v", schedule.Slots)
+	}
+	if schedule.Shortfall != 0 {
+		t.Errorf("expected no shortfall, got %.2f", schedule.Shortfall)
+	}
+}
+
+func TestPlannerCheck(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	const product, tariff = "AGILE-18-02-21", "E-1R-AGILE-18-02-21-C"
+	srv.AddTariffCharges(product, octopusenergy.FuelTypeElectricity, tariff, octopusenergy.RateStandardUnit, halfHourly(midnight, 20, 20, 20, 20)...)
+
+	p := NewPlanner(srv.Client(), octopusenergy.TariffChargesGetOptions{
+		ProductCode: product,
+		TariffCode:  tariff,
+		FuelType:    octopusenergy.FuelTypeElectricity,
+		Rate:        octopusenergy.RateStandardUnit,
+	}, ChargeOptions{
+		Battery: Battery{Capacity: 6, ChargePower: 3, StateOfCharge: 0},
+		ReadyBy: midnight.Add(4 * time.Hour),
+	})
+	p.now = func() time.Time { return midnight }
+
+	schedule, changed, err := p.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || schedule.Complete || !near(schedule.Energy, 0) {
+		t.Fatalf("expected to wait for the cheaper time after the published rates, got %+v", schedule)
+	}
+
+	if _, changed, err := p.Check(context.Background()); err != nil || changed {
+		t.Fatalf("expected no new plan without new rates, got %v %v", changed, err)
+	}
+
+	// the next rates are published
+	srv.AddTariffCharges(product, octopusenergy.FuelTypeElectricity, tariff, octopusenergy.RateStandardUnit, halfHourly(midnight.Add(2*time.Hour), 10, 10, 30, 30)...)
+	schedule, changed, err = p.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || !schedule.Complete || !near(schedule.Energy, 6) || !near(schedule.Cost, 3*10+3*20) {
+		t.Errorf("expected a new complete plan, got %+v", schedule)
+	}
+}
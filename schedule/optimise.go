@@ -0,0 +1,99 @@
+package schedule
+
+import "math"
+
+// step is a period the battery is planned over.
+type step struct {
+	hours float64
+
+	// What the home uses less what it generates in kWh, negative when there is surplus generation.
+	net float64
+
+	// What imported energy costs and exported energy earns, in pence per kWh.
+	importPrice float64
+	exportPrice float64
+
+	// Whether the battery may discharge during the step.
+	discharge bool
+}
+
+// flow returns the energy imported from the grid, negative when exporting, and its cost when the
+// battery stores the given energy during the step, negative energy being discharged.
+func (s step) flow(stored, efficiency float64) (grid, cost float64) {
+	grid = s.net + gridEnergy(stored, efficiency)
+	if grid > 0 {
+		return grid, grid * s.importPrice
+	}
+	return grid, grid * s.exportPrice
+}
+
+// optimise returns the cheapest state of charge, as a number of resolution steps, at the start and
+// end of every step, the end being at least target if that can be reached and as high as possible
+// otherwise, and whether target was reached.
+func optimise(steps []step, b Battery, res float64, target int) ([]int, bool) {
+	levels := int(math.Round(b.Capacity / res))
+	floor := int(math.Ceil(b.MinStateOfCharge/res - 1e-9))
+	start := clamp(int(math.Round(b.StateOfCharge/res)), 0, levels)
+	target = clamp(target, 0, levels)
+
+	// cost[i] is the cheapest way to be at level i, choice[n*(levels+1)+i] the level before step n
+	cost := make([]float64, levels+1)
+	next := make([]float64, levels+1)
+	for i := range cost {
+		cost[i] = math.Inf(1)
+	}
+	cost[start] = 0
+	choice := make([]int32, len(steps)*(levels+1))
+
+	for n, s := range steps {
+		up := int(math.Floor(b.ChargePower*s.hours*b.Efficiency/res + 1e-9))
+		down := 0
+		if s.discharge {
+			down = int(math.Floor(b.DischargePower*s.hours/res + 1e-9))
+		}
+
+		for i := range next {
+			next[i] = math.Inf(1)
+		}
+		choices := choice[n*(levels+1) : (n+1)*(levels+1)]
+		// highest levels first so that, between equally cheap plans, charging happens sooner
+		for cur := levels; cur >= 0; cur-- {
+			if math.IsInf(cost[cur], 1) {
+				continue
+			}
+			lo := cur - down
+			if lo < floor {
+				lo = int(math.Min(float64(cur), float64(floor)))
+			}
+			hi := int(math.Min(float64(cur+up), float64(levels)))
+			for to := lo; to <= hi; to++ {
+				_, c := s.flow(float64(to-cur)*res, b.Efficiency)
+				if c += cost[cur]; c < next[to] {
+					next[to] = c
+					choices[to] = int32(cur)
+				}
+			}
+		}
+		cost, next = next, cost
+	}
+
+	// the cheapest level that meets the target, or the highest reachable if none do
+	end, reached := -1, true
+	for i := target; i <= levels; i++ {
+		if !math.IsInf(cost[i], 1) && (end < 0 || cost[i] < cost[end]) {
+			end = i
+		}
+	}
+	for i := target - 1; end < 0 && i >= 0; i-- {
+		if !math.IsInf(cost[i], 1) {
+			end, reached = i, false
+		}
+	}
+
+	path := make([]int, len(steps)+1)
+	path[len(steps)] = end
+	for n := len(steps) - 1; n >= 0; n-- {
+		path[n] = int(choice[n*(levels+1)+path[n+1]])
+	}
+	return path, reached
+}
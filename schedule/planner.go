@@ -0,0 +1,165 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+const defaultInterval = 15 * time.Minute
+
+// Planner fetches unit rates and plans a charge, planning it again each time more rates are
+// published, such as Agile's rates for the next day each afternoon.
+type Planner struct {
+	// Client used to fetch tariff charges.
+	Client *octopusenergy.Client
+
+	// Options selects the rates to charge at, example an Agile standard unit rate. The period is
+	// set on each fetch.
+	Options octopusenergy.TariffChargesGetOptions
+
+	// ExportOptions selects the rates discharged energy is worth, example Agile Outgoing. Optional.
+	ExportOptions *octopusenergy.TariffChargesGetOptions
+
+	// The battery and deadline. Rates, ExportRates and From are set on each plan.
+	Charge ChargeOptions
+
+	// Returns the battery's current state of charge in kWh before each plan. Optional, by default
+	// the state of charge the previous schedule expected is used.
+	StateOfCharge func(ctx context.Context) (float64, error)
+
+	// How often Run checks for new rates. Defaults to 15 minutes.
+	Interval time.Duration
+
+	// Called with any error from a check in Run, which otherwise keeps going. Optional.
+	OnError func(err error)
+
+	schedule *Schedule
+	ratesTo  time.Time
+	now      func() time.Time
+}
+
+// NewPlanner returns a Planner for the given tariff and battery with default settings.
+func NewPlanner(client *octopusenergy.Client, options octopusenergy.TariffChargesGetOptions, charge ChargeOptions) *Planner {
+	return &Planner{
+		Client:   client,
+		Options:  options,
+		Charge:   charge,
+		Interval: defaultInterval,
+	}
+}
+
+// Plan fetches the rates from now until the deadline and plans the charge.
+func (p *Planner) Plan(ctx context.Context) (*Schedule, error) {
+	now := p.clock()
+	rates, err := p.rates(ctx, p.Options, now)
+	if err != nil {
+		return nil, err
+	}
+	charge := p.Charge
+	charge.From = now
+	charge.Rates = rates
+	if p.ExportOptions != nil {
+		if charge.ExportRates, err = p.rates(ctx, *p.ExportOptions, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.StateOfCharge != nil {
+		if charge.Battery.StateOfCharge, err = p.StateOfCharge(ctx); err != nil {
+			return nil, err
+		}
+	} else if p.schedule != nil {
+		charge.Battery.StateOfCharge = p.schedule.StateOfChargeAt(now, p.Charge.Battery.StateOfCharge)
+	}
+
+	schedule, err := PlanCharge(charge)
+	if err != nil {
+		return nil, err
+	}
+	p.Charge.Battery.StateOfCharge = charge.Battery.StateOfCharge
+	p.schedule = schedule
+	p.ratesTo = latest(rates)
+	return schedule, nil
+}
+
+// Check plans the charge if it has not been planned yet or rates have been published since it
+// was, returning the new schedule and true, or the current schedule and false.
+func (p *Planner) Check(ctx context.Context) (*Schedule, bool, error) {
+	if p.schedule != nil {
+		rates, err := p.rates(ctx, p.Options, p.clock())
+		if err != nil {
+			return p.schedule, false, err
+		}
+		if !latest(rates).After(p.ratesTo) {
+			return p.schedule, false, nil
+		}
+	}
+
+	schedule, err := p.Plan(ctx)
+	if err != nil {
+		return p.schedule, false, err
+	}
+	return schedule, true, nil
+}
+
+// Run calls Check every Interval until the context is cancelled, calling onPlan with each new
+// schedule.
+func (p *Planner) Run(ctx context.Context, onPlan func(*Schedule)) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		schedule, changed, err := p.Check(ctx)
+		if err != nil && p.OnError != nil && ctx.Err() == nil {
+			p.OnError(err)
+		}
+		if changed {
+			onPlan(schedule)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Planner) rates(ctx context.Context, options octopusenergy.TariffChargesGetOptions, now time.Time) ([]octopusenergy.TariffCharge, error) {
+	// GetPages mutates its options, so work on a copy
+	options.PeriodFrom = octopusenergy.Time(now.UTC().Truncate(slotLength))
+	options.PeriodTo = octopusenergy.Time(p.Charge.ReadyBy.UTC())
+	res, err := p.Client.TariffCharge.GetPagesWithContext(ctx, &options)
+	if err != nil {
+		return nil, err
+	}
+	return res.Results, nil
+}
+
+// latest returns the end of the last rate, the zero time if any rate has no end.
+func latest(rates []octopusenergy.TariffCharge) time.Time {
+	var to time.Time
+	for _, r := range rates {
+		if r.ValidTo.IsZero() {
+			return time.Time{}
+		}
+		if r.ValidTo.After(to) {
+			to = r.ValidTo
+		}
+	}
+	return to
+}
+
+func (p *Planner) clock() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
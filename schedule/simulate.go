@@ -0,0 +1,187 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+// SimulationOptions is the options for Simulate.
+type SimulationOptions struct {
+	// The battery to simulate. Its capacity is replaced by each of Capacities.
+	Battery Battery
+
+	// The usable capacities to compare, in kWh. Default is the battery's capacity.
+	Capacities []float64
+
+	// What the home used each half hour. For a home with solar panels this is import plus
+	// generation less export.
+	Demand []octopusenergy.ConsumptionInterval
+
+	// Solar generation, for example from ReadIntervalsCSV. Optional.
+	Generation []octopusenergy.ConsumptionInterval
+
+	// Import unit rates, in any order, for example from TariffChargeService.GetForProduct.
+	ImportRates []octopusenergy.TariffCharge
+
+	// Export unit rates, in any order. Optional, without them exported energy earns nothing.
+	ExportRates []octopusenergy.TariffCharge
+
+	// The installed cost of the battery in pence, a fixed part and a part per kWh of capacity, used
+	// to estimate payback.
+	FixedCost  float64
+	CostPerKWh float64
+
+	// The state of charge is planned in steps of this many kWh. Default is 0.1.
+	Resolution float64
+}
+
+// SimulationResult is the outcome of a simulation with one battery capacity.
+type SimulationResult struct {
+	// Usable capacity in kWh, zero without a battery.
+	Capacity float64
+
+	// Energy imported and exported, in kWh.
+	Import float64
+	Export float64
+
+	// What imports cost less what exports earned, in pence.
+	Cost float64
+
+	// How much less the home paid than without a battery, in pence, over the simulation and scaled
+	// to a year.
+	Savings       float64
+	AnnualSavings float64
+
+	// Energy discharged divided by capacity, the number of full cycles.
+	Cycles float64
+
+	// Years for the annual savings to pay for the battery, zero if no cost was given or it never pays
+	// for itself.
+	PaybackYears float64
+}
+
+// Simulation compares batteries of different capacities against none.
+type Simulation struct {
+	// The home without a battery.
+	Baseline SimulationResult
+
+	// One result for each capacity, in the order given.
+	Results []SimulationResult
+}
+
+// Simulate replays historic demand and generation with a battery that charges from the grid or
+// surplus generation when import is cheap, and powers the home or exports when energy is worth
+// more. The battery is run as well as possible knowing the whole period's demand, generation and
+// rates in advance, so savings are an upper bound for what a real controller achieves.
+func Simulate(options SimulationOptions) (*Simulation, error) {
+	if len(options.Demand) == 0 {
+		return nil, errors.New("no demand to simulate")
+	}
+	if options.Resolution <= 0 {
+		options.Resolution = 0.1
+	}
+	capacities := options.Capacities
+	if len(capacities) == 0 {
+		capacities = []float64{options.Battery.Capacity}
+	}
+
+	generation := map[int64]float64{}
+	for _, g := range options.Generation {
+		generation[g.Start.Unix()] += g.Consumption
+	}
+
+	importRates, exportRates := octopusenergy.NewChargeIndex(options.ImportRates), octopusenergy.NewChargeIndex(options.ExportRates)
+	demand := sortedDemand(options.Demand)
+	steps := make([]step, len(demand))
+	for n, d := range demand {
+		rate, ok := importRates.At(d.Start)
+		if !ok {
+			return nil, fmt.Errorf("no import rate at %s", d.Start.Format(time.RFC3339))
+		}
+		exportRate, _ := exportRates.At(d.Start)
+		steps[n] = step{
+			hours:       d.End.Sub(d.Start).Hours(),
+			net:         d.Consumption - generation[d.Start.Unix()],
+			importPrice: rate.ValueIncVat,
+			exportPrice: exportRate.ValueIncVat,
+			discharge:   true,
+		}
+	}
+	years := demand[len(demand)-1].End.Sub(demand[0].Start).Hours() / (24 * 365)
+
+	out := Simulation{Baseline: simulateBattery(steps, nil, 0, options.Resolution, 1)}
+	for _, capacity := range capacities {
+		b := options.Battery
+		b.Capacity = capacity
+		if b.Efficiency <= 0 || b.Efficiency > 1 {
+			b.Efficiency = 1
+		}
+		if b.StateOfCharge > capacity {
+			b.StateOfCharge = capacity
+		}
+		if capacity <= 0 || b.ChargePower <= 0 {
+			return nil, errors.New("battery capacity and charge power must be above zero")
+		}
+
+		// finish no emptier than it started, so stored energy is not counted as a saving
+		start := int(math.Round(b.StateOfCharge / options.Resolution))
+		path, _ := optimise(steps, b, options.Resolution, start)
+		r := simulateBattery(steps, path, capacity, options.Resolution, b.Efficiency)
+		r.Savings = out.Baseline.Cost - r.Cost
+		r.AnnualSavings = r.Savings / years
+		if cost := options.FixedCost + options.CostPerKWh*capacity; cost > 0 && r.AnnualSavings > 0 {
+			r.PaybackYears = cost / r.AnnualSavings
+		}
+		out.Results = append(out.Results, r)
+	}
+	return &out, nil
+}
+
+// simulateBattery totals the grid flows when the battery follows path, or without a battery if
+// path is nil.
+func simulateBattery(steps []step, path []int, capacity, res, efficiency float64) SimulationResult {
+	r := SimulationResult{Capacity: capacity}
+	var discharged float64
+	for n, s := range steps {
+		var stored float64
+		if path != nil {
+			stored = float64(path[n+1]-path[n]) * res
+		}
+		if stored < 0 {
+			discharged -= stored
+		}
+		grid, cost := s.flow(stored, efficiency)
+		if grid > 0 {
+			r.Import += grid
+		} else {
+			r.Export -= grid
+		}
+		r.Cost += cost
+	}
+	if capacity > 0 {
+		r.Cycles = discharged / capacity
+	}
+	return r
+}
+
+// sortedDemand returns the demand ordered by start time without duplicate intervals.
+func sortedDemand(demand []octopusenergy.ConsumptionInterval) []octopusenergy.ConsumptionInterval {
+	sorted := append([]octopusenergy.ConsumptionInterval{}, demand...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	var out []octopusenergy.ConsumptionInterval
+	for _, d := range sorted {
+		if n := len(out); n > 0 && d.Start.Equal(out[n-1].Start) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+// demand returns days of half-hourly intervals from midnight, each the given kWh.
+func demand(days int, kwh func(at time.Time) float64) []octopusenergy.ConsumptionInterval {
+	var out []octopusenergy.ConsumptionInterval
+	for at := midnight; at.Before(midnight.AddDate(0, 0, days)); at = at.Add(slotLength) {
+		out = append(out, octopusenergy.ConsumptionInterval{Start: at, End: at.Add(slotLength), Consumption: kwh(at)})
+	}
+	return out
+}
+
+// economy7 returns import rates that are cheap from 2am to 5am.
+func economy7(days int) []octopusenergy.TariffCharge {
+	var rates []octopusenergy.TariffCharge
+	for at := midnight; at.Before(midnight.AddDate(0, 0, days)); at = at.Add(slotLength) {
+		price := 30.0
+		if h := at.Hour(); h >= 2 && h < 5 {
+			price = 5
+		}
+		rates = append(rates, halfHourly(at, price)...)
+	}
+	return rates
+}
+
+func TestSimulate(t *testing.T) {
+	sim, err := Simulate(SimulationOptions{
+		Battery:     Battery{ChargePower: 10, DischargePower: 10},
+		Capacities:  []float64{5, 10},
+		Demand:      demand(2, func(time.Time) float64 { return 0.5 }),
+		ImportRates: economy7(2),
+		FixedCost:   100000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !near(sim.Baseline.Cost, 2*(6*0.5*5+42*0.5*30)) || !near(sim.Baseline.Import, 48) {
+		t.Errorf("unexpected baseline %+v", sim.Baseline)
+	}
+	// each night the battery fills at 5p to save 30p later in the day
+	for i, capacity := range []float64{5, 10} {
+		r := sim.Results[i]
+		if !near(r.Savings, 2*capacity*25) || r.Cycles < 2-1e-9 {
+			t.Errorf("%.0f kWh: expected to save %.0fp over at least 2 cycles, got %+v", capacity, 2*capacity*25, r)
+		}
+		if annual := 2 * capacity * 25 * 365 / 2; !near(r.AnnualSavings, annual) || !near(r.PaybackYears, 100000/annual) {
+			t.Errorf("%.0f kWh: unexpected annual savings %+v", capacity, r)
+		}
+	}
+}
+
+func TestSimulateSolar(t *testing.T) {
+	options := SimulationOptions{
+		Battery:    Battery{ChargePower: 3, DischargePower: 3, Efficiency: 0.9},
+		Capacities: []float64{2.5, 5, 10},
+		Demand:     demand(7, func(time.Time) float64 { return 0.4 }),
+		Generation: demand(7, func(at time.Time) float64 {
+			if h := at.Hour(); h >= 10 && h < 15 {
+				return 1.2
+			}
+			return 0
+		}),
+		ImportRates: economy7(7),
+		ExportRates: halfHourly(midnight, 15)[:1],
+	}
+	options.ExportRates[0].ValidTo = time.Time{}
+
+	sim, err := Simulate(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(sim.Baseline.Export, 7*10*0.8) {
+		t.Errorf("expected the solar surplus exported without a battery, got %+v", sim.Baseline)
+	}
+
+	previous := sim.Baseline
+	for _, r := range sim.Results {
+		if r.Savings < previous.Savings || r.Import > previous.Import {
+			t.Errorf("expected a %.1f kWh battery to do at least as well as %.1f kWh, got %+v and %+v", r.Capacity, previous.Capacity, r, previous)
+		}
+		previous = r
+	}
+	if sim.Results[0].Export >= sim.Baseline.Export {
+		t.Errorf("expected surplus stored rather than exported, got %+v", sim.Results[0])
+	}
+}
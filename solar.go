@@ -0,0 +1,260 @@
+package octopusenergy
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// A GenerationSource provides solar generation over a period, for example from an inverter's or
+// monitoring service's API. Intervals need not be half-hourly.
+type GenerationSource interface {
+	GenerationWithContext(ctx context.Context, from, to time.Time) ([]ConsumptionInterval, error)
+}
+
+// GenerationIntervals is a GenerationSource of generation already read, for example with
+// ReadIntervalsCSV.
+type GenerationIntervals []ConsumptionInterval
+
+// GenerationWithContext returns the intervals starting from from (inclusive) to to (exclusive). A
+// zero from or to is unbounded.
+func (g GenerationIntervals) GenerationWithContext(_ context.Context, from, to time.Time) ([]ConsumptionInterval, error) {
+	var out []ConsumptionInterval
+	for _, in := range g {
+		if (!from.IsZero() && in.Start.Before(from)) || (!to.IsZero() && !in.Start.Before(to)) {
+			continue
+		}
+		out = append(out, in)
+	}
+	return out, nil
+}
+
+// SolarOptions is the options for AnalyseSolar.
+type SolarOptions struct {
+	// Energy imported from and exported to the grid, from the import and export meters.
+	Import []ConsumptionInterval
+	Export []ConsumptionInterval
+
+	// Energy generated by the solar panels.
+	Generation []ConsumptionInterval
+
+	// Export unit rates, in any order. Exports are not priced without them.
+	ExportRates []TariffCharge
+
+	// The location days and months are in. Default is UKLocation.
+	Location *time.Location
+}
+
+// SolarSummary is how a home with solar panels used its generation over a period. Energy is in kWh.
+type SolarSummary struct {
+	// The period, from (inclusive) to (exclusive).
+	From time.Time
+	To   time.Time
+
+	Generation float64
+	Import     float64
+	Export     float64
+
+	// Generation used by the home rather than exported.
+	SelfConsumption float64
+
+	// Everything the home used, from the grid and the panels.
+	Consumption float64
+
+	// The fraction of generation used by the home, and the fraction of the home's use met by
+	// generation, between 0 and 1. Zero when there was no generation or use.
+	SelfConsumptionRatio float64
+	SelfSufficiency      float64
+
+	// What exports earned, in pence.
+	ExportEarningsExcVat float64
+	ExportEarningsIncVat float64
+}
+
+// SolarReport is the self-consumption of a home with solar panels, for each day and month and in total.
+type SolarReport struct {
+	// Days and months with any generation, import or export, in order.
+	Days   []SolarSummary
+	Months []SolarSummary
+
+	Total SolarSummary
+
+	// Exports that had no export rate covering their start time and so earned nothing.
+	Unpriced []ConsumptionInterval
+}
+
+// AnalyseSolar combines solar generation with import and export readings to find how much of the
+// generation the home used itself, how much of its use the panels met and what exports earned.
+// Self-consumption is generation less export for each day, so generation and meter readings
+// need only agree over a day, not each half hour.
+func AnalyseSolar(options SolarOptions) SolarReport {
+	loc := options.Location
+	if loc == nil {
+		loc = UKLocation()
+	}
+
+	var report SolarReport
+	days := map[int64]*SolarSummary{}
+	day := func(t time.Time) *SolarSummary {
+		from := startOfDay(t, loc)
+		d, ok := days[from.Unix()]
+		if !ok {
+			d = &SolarSummary{From: from, To: from.AddDate(0, 0, 1)}
+			days[from.Unix()] = d
+		}
+		return d
+	}
+
+	for _, in := range options.Generation {
+		day(in.Start).Generation += in.Consumption
+	}
+	for _, in := range options.Import {
+		day(in.Start).Import += in.Consumption
+	}
+	rates := NewChargeIndex(options.ExportRates)
+	for _, in := range options.Export {
+		d := day(in.Start)
+		d.Export += in.Consumption
+		rate, ok := rates.At(in.Start)
+		if !ok {
+			report.Unpriced = append(report.Unpriced, in)
+			continue
+		}
+		d.ExportEarningsExcVat += in.Consumption * rate.ValueExcVat
+		d.ExportEarningsIncVat += in.Consumption * rate.ValueIncVat
+	}
+
+	for _, d := range days {
+		// exports above generation, such as from a battery, are not self-consumption
+		if d.SelfConsumption = d.Generation - d.Export; d.SelfConsumption < 0 {
+			d.SelfConsumption = 0
+		}
+		d.Consumption = d.Import + d.SelfConsumption
+		d.ratios()
+		report.Days = append(report.Days, *d)
+	}
+	sort.Slice(report.Days, func(i, j int) bool {
+		return report.Days[i].From.Before(report.Days[j].From)
+	})
+
+	for _, d := range report.Days {
+		from := time.Date(d.From.Year(), d.From.Month(), 1, 0, 0, 0, 0, loc)
+		if n := len(report.Months); n == 0 || !report.Months[n-1].From.Equal(from) {
+			report.Months = append(report.Months, SolarSummary{From: from, To: from.AddDate(0, 1, 0)})
+		}
+		report.Months[len(report.Months)-1].add(d)
+	}
+	for i := range report.Months {
+		report.Months[i].ratios()
+		report.Total.add(report.Months[i])
+	}
+	if n := len(report.Months); n > 0 {
+		report.Total.From, report.Total.To = report.Months[0].From, report.Months[n-1].To
+	}
+	report.Total.ratios()
+	return report
+}
+
+// add adds the energy and earnings of another period.
+func (s *SolarSummary) add(o SolarSummary) {
+	s.Generation += o.Generation
+	s.Import += o.Import
+	s.Export += o.Export
+	s.SelfConsumption += o.SelfConsumption
+	s.Consumption += o.Consumption
+	s.ExportEarningsExcVat += o.ExportEarningsExcVat
+	s.ExportEarningsIncVat += o.ExportEarningsIncVat
+}
+
+func (s *SolarSummary) ratios() {
+	s.SelfConsumptionRatio, s.SelfSufficiency = 0, 0
+	if s.Generation > 0 {
+		s.SelfConsumptionRatio = s.SelfConsumption / s.Generation
+	}
+	if s.Consumption > 0 {
+		s.SelfSufficiency = s.SelfConsumption / s.Consumption
+	}
+}
+
+// ConsumptionSolarGetOptions is the options for GetSolar.
+type ConsumptionSolarGetOptions struct {
+	// The import meter point's MPAN and meter serial number.
+	ImportMPN          string
+	ImportSerialNumber string
+
+	// The export meter point's MPAN and meter serial number.
+	ExportMPN          string
+	ExportSerialNumber string
+
+	// Where solar generation comes from, for example GenerationIntervals read with ReadIntervalsCSV.
+	Generation GenerationSource
+
+	// Export unit rates, for example from TariffChargeService.GetForProduct. Optional.
+	ExportRates []TariffCharge
+
+	// The location days and months are in. Default is UKLocation.
+	Location *time.Location
+
+	// Analyse from the given datetime (inclusive).
+	PeriodFrom *time.Time
+
+	// Analyse to the given datetime (exclusive).
+	PeriodTo *time.Time
+}
+
+// GetSolar fetches import and export consumption for a home with solar panels and analyses it with
+// its generation. See AnalyseSolar.
+func (s *ConsumptionService) GetSolar(options *ConsumptionSolarGetOptions) (*SolarReport, error) {
+	return s.GetSolarWithContext(context.Background(), options)
+}
+
+// GetSolarWithContext same as GetSolar except it takes a Context.
+func (s *ConsumptionService) GetSolarWithContext(ctx context.Context, options *ConsumptionSolarGetOptions) (*SolarReport, error) {
+	if options.Generation == nil {
+		return nil, errors.New("no generation source given")
+	}
+
+	meter := func(mpn, serial string) ([]ConsumptionInterval, error) {
+		res, err := s.GetPagesWithContext(ctx, &ConsumptionGetOptions{
+			MPN:          mpn,
+			SerialNumber: serial,
+			FuelType:     FuelTypeElectricity,
+			PeriodFrom:   options.PeriodFrom,
+			PeriodTo:     options.PeriodTo,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.Intervals()
+	}
+	imports, err := meter(options.ImportMPN, options.ImportSerialNumber)
+	if err != nil {
+		return nil, err
+	}
+	exports, err := meter(options.ExportMPN, options.ExportSerialNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var from, to time.Time
+	if options.PeriodFrom != nil {
+		from = *options.PeriodFrom
+	}
+	if options.PeriodTo != nil {
+		to = *options.PeriodTo
+	}
+	generation, err := options.Generation.GenerationWithContext(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := AnalyseSolar(SolarOptions{
+		Import:      imports,
+		Export:      exports,
+		Generation:  generation,
+		ExportRates: options.ExportRates,
+		Location:    options.Location,
+	})
+	return &report, nil
+}
@@ -0,0 +1,65 @@
+package octopusenergy_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestConsumptionGetSolar(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	// midnight on 1 June in the UK
+	from := time.Date(2021, 5, 31, 23, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1000000000001", "21L0000001", intervalsBetween(from, to, 0.2)...)
+
+	var generation, exports []octopusenergy.ConsumptionInterval
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		noon := day.Add(11 * time.Hour)
+		generation = append(generation, intervalsBetween(noon, noon.Add(4*time.Hour), 1)...)
+		exports = append(exports, intervalsBetween(noon, noon.Add(4*time.Hour), 0.5)...)
+	}
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1000000000002", "21L0000001", exports...)
+
+	report, err := srv.Client().Consumption.GetSolar(&octopusenergy.ConsumptionSolarGetOptions{
+		ImportMPN:          "1000000000001",
+		ImportSerialNumber: "21L0000001",
+		ExportMPN:          "1000000000002",
+		ExportSerialNumber: "21L0000001",
+		Generation:         octopusenergy.GenerationIntervals(generation),
+		// only the first day has a published export rate
+		ExportRates: []octopusenergy.TariffCharge{{ValueExcVat: 15, ValueIncVat: 15, ValidFrom: from, ValidTo: from.AddDate(0, 0, 1)}},
+		PeriodFrom:  octopusenergy.Time(from),
+		PeriodTo:    octopusenergy.Time(to),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Days) != 2 || len(report.Months) != 1 {
+		t.Fatalf("expected 2 days in 1 month, got %d and %d", len(report.Days), len(report.Months))
+	}
+	day := report.Days[0]
+	if !day.From.Equal(from) || day.Generation != 8 || day.Export != 4 || day.SelfConsumption != 4 {
+		t.Errorf("unexpected day %+v", day)
+	}
+	if math.Abs(day.Consumption-13.6) > 1e-9 || day.SelfConsumptionRatio != 0.5 || math.Abs(day.SelfSufficiency-4/13.6) > 1e-9 {
+		t.Errorf("unexpected ratios %+v", day)
+	}
+	if day.ExportEarningsIncVat != 60 || report.Days[1].ExportEarningsIncVat != 0 || len(report.Unpriced) != 8 {
+		t.Errorf("expected only the first day's exports priced, got %+v with %d unpriced", report.Days, len(report.Unpriced))
+	}
+
+	month := report.Months[0]
+	if month.From.Month() != time.June || month.Generation != 16 || month.SelfConsumptionRatio != 0.5 || month.ExportEarningsIncVat != 60 {
+		t.Errorf("unexpected month %+v", month)
+	}
+	if report.Total.Generation != 16 || math.Abs(report.Total.Import-19.2) > 1e-9 {
+		t.Errorf("unexpected total %+v", report.Total)
+	}
+}
@@ -0,0 +1,209 @@
+package octopusenergy
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// StitchedInterval is a consumption interval and the meter it was read from.
+type StitchedInterval struct {
+	ConsumptionInterval
+	SerialNumber string
+}
+
+// MeterPeriod is a period during which consumption came from a single meter.
+type MeterPeriod struct {
+	SerialNumber string
+	From         time.Time
+	To           time.Time
+}
+
+// ConsumptionStitchedGetOptions is the options for GetStitched.
+type ConsumptionStitchedGetOptions struct {
+	// The Meter Point Number this is the electricity meter-point’s MPAN or gas meter-point’s MPRN
+	MPN string
+
+	// The serial numbers of every meter installed at the meter point, in any order.
+	SerialNumbers []string
+
+	// Fueltype: electricity or gas
+	FuelType FuelType
+
+	// Show consumption from the given datetime (inclusive).
+	PeriodFrom *time.Time
+
+	// Show consumption to the given datetime (exclusive).
+	PeriodTo *time.Time
+}
+
+// ConsumptionStitchedGetOutput is the returned struct from GetStitched.
+type ConsumptionStitchedGetOutput struct {
+	// One continuous series ordered by start time, without overlapping intervals.
+	Results []StitchedInterval
+
+	// Which meter the results came from over time, in order.
+	Periods []MeterPeriod
+}
+
+// GetStitched fetches the consumption of every meter on a meter point and stitches it into one series,
+// for meter points whose meter has been exchanged. See StitchConsumption for how meters are chosen.
+func (s *ConsumptionService) GetStitched(options *ConsumptionStitchedGetOptions) (*ConsumptionStitchedGetOutput, error) {
+	return s.GetStitchedWithContext(context.Background(), options)
+}
+
+// GetStitchedWithContext same as GetStitched except it takes a Context.
+func (s *ConsumptionService) GetStitchedWithContext(ctx context.Context, options *ConsumptionStitchedGetOptions) (*ConsumptionStitchedGetOutput, error) {
+	if len(options.SerialNumbers) == 0 {
+		return nil, errors.New("no serial numbers given")
+	}
+
+	bySerial := map[string][]ConsumptionInterval{}
+	installed := map[string]time.Time{}
+	for _, serial := range options.SerialNumbers {
+		res, err := s.GetPagesWithContext(ctx, &ConsumptionGetOptions{
+			MPN:          options.MPN,
+			SerialNumber: serial,
+			FuelType:     options.FuelType,
+			PeriodFrom:   options.PeriodFrom,
+			PeriodTo:     options.PeriodTo,
+		})
+		if isNotFound(err) {
+			// meters that never sent data are not found
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if bySerial[serial], err = res.Intervals(); err != nil {
+			return nil, err
+		}
+
+		if options.PeriodFrom == nil {
+			continue
+		}
+		// the data requested may start after a meter exchange, so look up when each meter first
+		// reported to tell which was installed first
+		first, err := s.GetWithContext(ctx, &ConsumptionGetOptions{
+			MPN:          options.MPN,
+			SerialNumber: serial,
+			FuelType:     options.FuelType,
+			PageSize:     Int(1),
+			OrderBy:      String("period"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		intervals, err := first.Intervals()
+		if err != nil {
+			return nil, err
+		}
+		if len(intervals) > 0 {
+			installed[serial] = intervals[0].Start
+		}
+	}
+
+	results := StitchConsumptionInstalled(bySerial, installed)
+	return &ConsumptionStitchedGetOutput{Results: results, Periods: MeterPeriods(results)}, nil
+}
+
+// StitchConsumption merges the consumption of several meters on the same meter point into one series.
+// Each meter is taken to be installed when its data starts and removed when the next meter's data
+// starts, and only its intervals within that period are used, since an old meter often keeps
+// reporting zeros after it is removed. Meters whose data starts at the same time, as when the
+// intervals given start after an exchange, are ordered with one reporting only zeros first, then
+// by which stops reporting first. Gaps in a meter's data while it was installed are left as gaps,
+// see CheckQuality to find them. Overlapping and duplicate intervals are dropped.
+func StitchConsumption(bySerial map[string][]ConsumptionInterval) []StitchedInterval {
+	return StitchConsumptionInstalled(bySerial, nil)
+}
+
+// StitchConsumptionInstalled same as StitchConsumption except meters are taken to be installed at
+// the given times, such as their first ever reading, rather than when their data in bySerial starts.
+// Use it when the intervals only cover part of the time the meters were installed. Meters missing
+// from installed are taken to be installed when their data starts.
+func StitchConsumptionInstalled(bySerial map[string][]ConsumptionInterval, installed map[string]time.Time) []StitchedInterval {
+	type meter struct {
+		serial    string
+		installed time.Time
+		lastEnd   time.Time
+		zeros     bool
+	}
+	var meters []meter
+	for serial, intervals := range bySerial {
+		if len(intervals) == 0 {
+			continue
+		}
+		m := meter{serial: serial, installed: intervals[0].Start, zeros: true}
+		for _, in := range intervals {
+			if in.Start.Before(m.installed) {
+				m.installed = in.Start
+			}
+			if in.End.After(m.lastEnd) {
+				m.lastEnd = in.End
+			}
+			if in.Consumption != 0 {
+				m.zeros = false
+			}
+		}
+		if t, ok := installed[serial]; ok && !t.IsZero() {
+			m.installed = t
+		}
+		meters = append(meters, m)
+	}
+	// in order of installation, a removed meter may still report zeros or stop before its replacement
+	sort.Slice(meters, func(i, j int) bool {
+		a, b := meters[i], meters[j]
+		switch {
+		case !a.installed.Equal(b.installed):
+			return a.installed.Before(b.installed)
+		case a.zeros != b.zeros:
+			return a.zeros
+		case !a.lastEnd.Equal(b.lastEnd):
+			return a.lastEnd.Before(b.lastEnd)
+		}
+		return a.serial < b.serial
+	})
+
+	var all []StitchedInterval
+	for i, m := range meters {
+		var removed time.Time
+		if i+1 < len(meters) {
+			removed = meters[i+1].installed
+		}
+		for _, in := range bySerial[m.serial] {
+			if removed.IsZero() || in.Start.Before(removed) {
+				all = append(all, StitchedInterval{ConsumptionInterval: in, SerialNumber: m.serial})
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Start.Before(all[j].Start)
+	})
+
+	var out []StitchedInterval
+	var end time.Time
+	for _, in := range all {
+		if len(out) > 0 && in.Start.Before(end) {
+			continue
+		}
+		out = append(out, in)
+		end = in.End
+	}
+	return out
+}
+
+// MeterPeriods returns the runs of intervals read from the same meter, in order. A run spans any gaps
+// in that meter's data.
+func MeterPeriods(intervals []StitchedInterval) []MeterPeriod {
+	var periods []MeterPeriod
+	for _, in := range intervals {
+		if n := len(periods); n > 0 && periods[n-1].SerialNumber == in.SerialNumber {
+			periods[n-1].To = in.End
+			continue
+		}
+		periods = append(periods, MeterPeriod{SerialNumber: in.SerialNumber, From: in.Start, To: in.End})
+	}
+	return periods
+}
@@ -0,0 +1,111 @@
+package octopusenergy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func intervalsBetween(from, to time.Time, kwh float64) []octopusenergy.ConsumptionInterval {
+	var out []octopusenergy.ConsumptionInterval
+	for at := from; at.Before(to); at = at.Add(30 * time.Minute) {
+		out = append(out, octopusenergy.ConsumptionInterval{Start: at, End: at.Add(30 * time.Minute), Consumption: kwh})
+	}
+	return out
+}
+
+func TestConsumptionGetStitched(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	day := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	swap := day.Add(24 * time.Hour)
+	gap := swap.Add(time.Hour)
+
+	// the old meter keeps reporting zeros for two hours after it is removed
+	old := append(intervalsBetween(day, swap, 0.5), intervalsBetween(swap, swap.Add(2*time.Hour), 0)...)
+	// the new meter misses a half hour just after the swap and repeats another
+	replacement := append(intervalsBetween(swap, gap, 1), intervalsBetween(gap.Add(30*time.Minute), swap.Add(24*time.Hour), 1)...)
+	replacement = append(replacement, replacement[len(replacement)-1])
+
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111111", "OLD1111111", old...)
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111111", "NEW2222222", replacement...)
+
+	res, err := srv.Client().Consumption.GetStitched(&octopusenergy.ConsumptionStitchedGetOptions{
+		MPN:           "1111111111111",
+		SerialNumbers: []string{"NEW2222222", "OLD1111111", "UNUSED0000"},
+		FuelType:      octopusenergy.FuelTypeElectricity,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Results) != 95 {
+		t.Fatalf("expected 2 days of intervals less the missed half hour, got %d", len(res.Results))
+	}
+	// the old meter's zeros are not used to fill the new meter's gap
+	var gaps []time.Time
+	for i, in := range res.Results {
+		if in.SerialNumber == "OLD1111111" && !in.Start.Before(swap) {
+			t.Errorf("unexpected interval from the removed meter at %s", in.Start)
+		}
+		if i > 0 && !in.Start.Equal(res.Results[i-1].End) {
+			gaps = append(gaps, res.Results[i-1].End)
+		}
+	}
+	if len(gaps) != 1 || !gaps[0].Equal(gap) {
+		t.Errorf("expected a single gap at %s, got %v", gap, gaps)
+	}
+
+	want := []octopusenergy.MeterPeriod{
+		{SerialNumber: "OLD1111111", From: day, To: swap},
+		{SerialNumber: "NEW2222222", From: swap, To: swap.Add(24 * time.Hour)},
+	}
+	if len(res.Periods) != len(want) {
+		t.Fatalf("expected %d meter periods, got %+v", len(want), res.Periods)
+	}
+	for i, p := range res.Periods {
+		if p.SerialNumber != want[i].SerialNumber || !p.From.Equal(want[i].From) || !p.To.Equal(want[i].To) {
+			t.Errorf("period %d: expected %+v, got %+v", i, want[i], p)
+		}
+	}
+}
+
+func TestConsumptionGetStitchedAfterExchange(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	day := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	swap := day.Add(24 * time.Hour)
+	from := swap.Add(time.Hour)
+
+	// the removed meter keeps reporting a trickle for the rest of the second day, so within the period
+	// both meters start and end at the same time and the old meter's serial sorts after the new one's
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111111", "OLD1111111",
+		append(intervalsBetween(day, swap, 0.5), intervalsBetween(swap, swap.Add(24*time.Hour), 0.01)...)...)
+	srv.AddConsumption(octopusenergy.FuelTypeElectricity, "1111111111111", "NEW2222222", intervalsBetween(swap, swap.Add(24*time.Hour), 1)...)
+
+	res, err := srv.Client().Consumption.GetStitched(&octopusenergy.ConsumptionStitchedGetOptions{
+		MPN:           "1111111111111",
+		SerialNumbers: []string{"OLD1111111", "NEW2222222"},
+		FuelType:      octopusenergy.FuelTypeElectricity,
+		PeriodFrom:    &from,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Periods) != 1 || res.Periods[0].SerialNumber != "NEW2222222" || !res.Periods[0].From.Equal(from) || len(res.Results) != 46 {
+		t.Errorf("expected the new meter's data from %s, got %d intervals from %+v", from, len(res.Results), res.Periods)
+	}
+
+	// without installation times the meter reporting only zeros is taken to be the removed one
+	stitched := octopusenergy.StitchConsumption(map[string][]octopusenergy.ConsumptionInterval{
+		"OLD1111111": intervalsBetween(from, swap.Add(24*time.Hour), 0),
+		"NEW2222222": intervalsBetween(from, swap.Add(24*time.Hour), 1),
+	})
+	if periods := octopusenergy.MeterPeriods(stitched); len(periods) != 1 || periods[0].SerialNumber != "NEW2222222" {
+		t.Errorf("expected only the new meter, got %+v", periods)
+	}
+}
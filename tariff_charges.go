@@ -54,15 +54,19 @@ type TariffChargesGetOptions struct {
 
 // TariffChargesGetOutput is the returned struct from GetTariffCharges.
 type TariffChargesGetOutput struct {
-	Count    int    `json:"count"`
-	Next     string `json:"next"`
-	Previous string `json:"previous"`
-	Results  []struct {
-		ValueExcVat float64   `json:"value_exc_vat"`
-		ValueIncVat float64   `json:"value_inc_vat"`
-		ValidFrom   time.Time `json:"valid_from"`
-		ValidTo     time.Time `json:"valid_to"`
-	} `json:"results"`
+	Count    int            `json:"count"`
+	Next     string         `json:"next"`
+	Previous string         `json:"previous"`
+	Results  []TariffCharge `json:"results"`
+}
+
+// TariffCharge is a single charge, in pence, and the period it is valid for. A zero ValidTo means
+// the charge has no end date.
+type TariffCharge struct {
+	ValueExcVat float64   `json:"value_exc_vat"`
+	ValueIncVat float64   `json:"value_inc_vat"`
+	ValidFrom   time.Time `json:"valid_from"`
+	ValidTo     time.Time `json:"valid_to"`
 }
 
 // Get retrieves the details of a tariffs changes. This endpoint is paginated, it will return
@@ -88,7 +92,14 @@ func (s *TariffChargeService) GetWithContext(ctx context.Context, options *Tarif
 	}
 
 	res := TariffChargesGetOutput{}
-	if err := s.client.sendRequest(req, false, &res); err != nil {
+	op := Operation{
+		Service:      "TariffCharge",
+		Method:       "Get",
+		HTTPMethod:   http.MethodGet,
+		PathTemplate: "/v1/products/{product_code}/{fuel_type}-tariffs/{tariff_code}/{rate}/",
+		FuelType:     options.FuelType.String(),
+	}
+	if err := s.client.sendRequest(op, req, &res); err != nil {
 		return nil, err
 	}
 
@@ -102,17 +113,20 @@ func (s *TariffChargeService) GetPages(options *TariffChargesGetOptions) (*Tarif
 
 // GetPagesWithContext same as GetPages except it takes a Context
 func (s *TariffChargeService) GetPagesWithContext(ctx context.Context, options *TariffChargesGetOptions) (*TariffChargesGetOutput, error) {
+	ctx, span := s.client.startPagesSpan(ctx, Operation{Service: "TariffCharge", Method: "GetPages", PathTemplate: "/v1/products/{product_code}/{fuel_type}-tariffs/{tariff_code}/{rate}/", FuelType: options.FuelType.String()})
+	defer span.End()
+
 	options.PageSize = Int(1500)
 	options.Page = nil
 
 	fullResp := TariffChargesGetOutput{}
 	var lastPage bool
-	var i int
+	i := 1
 
 	for !lastPage {
 		page, err := s.GetWithContext(ctx, options)
 		if err != nil {
-			return nil, err
+			return nil, spanError(span, err)
 		}
 		fullResp.Count = page.Count
 		fullResp.Results = append(fullResp.Results, page.Results...)
@@ -123,5 +137,32 @@ func (s *TariffChargeService) GetPagesWithContext(ctx context.Context, options *
 		options.Page = Int(i)
 	}
 
+	endPagesSpan(span, len(fullResp.Results))
 	return &fullResp, nil
 }
+
+// GetAt returns the charge active at the given time, for example the current unit rate with time.Now.
+func (s *TariffChargeService) GetAt(options *TariffChargesGetOptions, at time.Time) (*TariffCharge, error) {
+	return s.GetAtWithContext(context.Background(), options, at)
+}
+
+// GetAtWithContext same as GetAt except it takes a Context.
+func (s *TariffChargeService) GetAtWithContext(ctx context.Context, options *TariffChargesGetOptions, at time.Time) (*TariffCharge, error) {
+	res, err := s.GetPagesWithContext(ctx, &TariffChargesGetOptions{
+		ProductCode: options.ProductCode,
+		TariffCode:  options.TariffCode,
+		FuelType:    options.FuelType,
+		Rate:        options.Rate,
+		PeriodFrom:  Time(at),
+		PeriodTo:    Time(at.Add(30 * time.Minute)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	charge, ok := ChargeAt(res.Results, at)
+	if !ok {
+		return nil, fmt.Errorf("no %s charge for tariff %s at %s", options.Rate, options.TariffCode, at.Format(time.RFC3339))
+	}
+	return &charge, nil
+}
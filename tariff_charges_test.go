@@ -0,0 +1,53 @@
+package octopusenergy_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+func TestTariffChargeGetAt(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	now := time.Date(2021, 2, 1, 12, 0, 0, 0, time.UTC)
+	srv.AddTariffCharges("VAR-19-04-12", octopusenergy.FuelTypeElectricity, "E-1R-VAR-19-04-12-C", octopusenergy.RateStandardUnit,
+		octopusenergy.TariffCharge{ValueExcVat: 18, ValueIncVat: 19, ValidFrom: now.AddDate(-1, 0, 0), ValidTo: now.AddDate(0, 0, -1)},
+		octopusenergy.TariffCharge{ValueExcVat: 19, ValueIncVat: 20, ValidFrom: now.AddDate(0, 0, -1)},
+	)
+	options := &octopusenergy.TariffChargesGetOptions{
+		ProductCode: "VAR-19-04-12",
+		TariffCode:  "E-1R-VAR-19-04-12-C",
+		FuelType:    octopusenergy.FuelTypeElectricity,
+		Rate:        octopusenergy.RateStandardUnit,
+	}
+	client := srv.Client()
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		price float64
+	}{
+		{name: "current", at: now, price: 20},
+		{name: "previous", at: now.AddDate(0, -1, 0), price: 19},
+		{name: "changeover", at: now.AddDate(0, 0, -1), price: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := client.TariffCharge.GetAt(options, tt.at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rate.ValueIncVat != tt.price {
+				t.Errorf("expected a rate of %vp, got %+v", tt.price, rate)
+			}
+		})
+	}
+
+	if _, err := client.TariffCharge.GetAt(options, now.AddDate(-2, 0, 0)); err == nil || !strings.Contains(err.Error(), "no standard-unit-rates charge") {
+		t.Errorf("expected no charge before the tariff started, got %v", err)
+	}
+}
@@ -0,0 +1,296 @@
+package octopusenergy
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+const (
+	defaultTelemetryPollInterval = 10 * time.Second
+	defaultTelemetryMaxBackoff   = 5 * time.Minute
+)
+
+// TelemetryService handles communication with the Octopus Home Mini related parts of the GraphQL API.
+type TelemetryService service
+
+// TelemetryDevicesListOptions is the options for ListDevices.
+type TelemetryDevicesListOptions struct {
+	// The octopus account number to search for devices.
+	AccountNumber string
+}
+
+// TelemetryDevicesListOutput is the returned struct from ListDevices.
+type TelemetryDevicesListOutput struct {
+	Devices []TelemetryDevice
+}
+
+// TelemetryDevice is a smart device, such as a Home Mini, that reports live readings for a meter.
+type TelemetryDevice struct {
+	DeviceID string
+
+	// The electricity meter-point’s MPAN the device reads from.
+	MPAN string
+
+	// The meter’s serial number.
+	SerialNumber string
+}
+
+const telemetryDevicesQuery = `query TelemetryDevices($accountNumber: String!) {
+  account(accountNumber: $accountNumber) {
+    electricityAgreements(active: true) {
+      meterPoint {
+        mpan
+        meters(includeInactive: false) {
+          serialNumber
+          smartDevices {
+            deviceId
+          }
+        }
+      }
+    }
+  }
+}`
+
+// ListDevices finds the devices on an account that can be used to read live telemetry.
+func (s *TelemetryService) ListDevices(options *TelemetryDevicesListOptions) (*TelemetryDevicesListOutput, error) {
+	return s.ListDevicesWithContext(context.Background(), options)
+}
+
+// ListDevicesWithContext same as ListDevices except it takes a Context.
+func (s *TelemetryService) ListDevicesWithContext(ctx context.Context, options *TelemetryDevicesListOptions) (*TelemetryDevicesListOutput, error) {
+	var res struct {
+		Account struct {
+			ElectricityAgreements []struct {
+				MeterPoint struct {
+					MPAN   string `json:"mpan"`
+					Meters []struct {
+						SerialNumber string `json:"serialNumber"`
+						SmartDevices []struct {
+							DeviceID string `json:"deviceId"`
+						} `json:"smartDevices"`
+					} `json:"meters"`
+				} `json:"meterPoint"`
+			} `json:"electricityAgreements"`
+		} `json:"account"`
+	}
+
+	vars := map[string]interface{}{"accountNumber": options.AccountNumber}
+	if err := s.client.GraphQL.QueryWithContext(ctx, telemetryDevicesQuery, vars, &res); err != nil {
+		return nil, err
+	}
+
+	out := TelemetryDevicesListOutput{}
+	for _, agreement := range res.Account.ElectricityAgreements {
+		for _, meter := range agreement.MeterPoint.Meters {
+			for _, device := range meter.SmartDevices {
+				out.Devices = append(out.Devices, TelemetryDevice{
+					DeviceID:     device.DeviceID,
+					MPAN:         agreement.MeterPoint.MPAN,
+					SerialNumber: meter.SerialNumber,
+				})
+			}
+		}
+	}
+
+	return &out, nil
+}
+
+// TelemetryGetOptions is the options for Get.
+type TelemetryGetOptions struct {
+	// The device to read, see ListDevices.
+	DeviceID string
+
+	// Show readings from the given datetime (inclusive).
+	Start *time.Time
+
+	// Show readings to the given datetime (exclusive).
+	End *time.Time
+
+	// Aggregates readings over a time period.
+	// Accepted values are: * ‘TEN_SECONDS’ * ‘ONE_MINUTE’ * ‘FIVE_MINUTES’ * ‘THIRTY_MINUTES’ * ‘ONE_HOUR’
+	Grouping *string
+}
+
+// TelemetryGetOutput is the returned struct from Get.
+type TelemetryGetOutput struct {
+	Readings []TelemetryReading `json:"smartMeterTelemetry"`
+}
+
+// TelemetryReading is a single near real time reading from a smart device.
+type TelemetryReading struct {
+	ReadAt time.Time
+
+	// Instantaneous demand, in watts.
+	Demand float64
+
+	// Cumulative consumption register reading, in Wh.
+	Consumption float64
+
+	// Consumption since the previous reading, in Wh.
+	ConsumptionDelta float64
+
+	// Cost of ConsumptionDelta, in pence.
+	CostDelta float64
+}
+
+// UnmarshalJSON decodes a reading, Kraken returns the numbers as strings.
+func (r *TelemetryReading) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ReadAt           string      `json:"readAt"`
+		Demand           krakenFloat `json:"demand"`
+		Consumption      krakenFloat `json:"consumption"`
+		ConsumptionDelta krakenFloat `json:"consumptionDelta"`
+		CostDelta        krakenFloat `json:"costDelta"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	readAt, err := parseKrakenTime(raw.ReadAt)
+	if err != nil {
+		return err
+	}
+
+	*r = TelemetryReading{
+		ReadAt:           readAt,
+		Demand:           float64(raw.Demand),
+		Consumption:      float64(raw.Consumption),
+		ConsumptionDelta: float64(raw.ConsumptionDelta),
+		CostDelta:        float64(raw.CostDelta),
+	}
+	return nil
+}
+
+const telemetryQuery = `query Telemetry($deviceId: String!, $start: DateTime, $end: DateTime, $grouping: TelemetryGrouping) {
+  smartMeterTelemetry(deviceId: $deviceId, start: $start, end: $end, grouping: $grouping) {
+    readAt
+    demand
+    consumption
+    consumptionDelta
+    costDelta
+  }
+}`
+
+// Get retrieves telemetry readings for a device. Without Start and End only the latest reading is returned.
+func (s *TelemetryService) Get(options *TelemetryGetOptions) (*TelemetryGetOutput, error) {
+	return s.GetWithContext(context.Background(), options)
+}
+
+// GetWithContext same as Get except it takes a Context.
+func (s *TelemetryService) GetWithContext(ctx context.Context, options *TelemetryGetOptions) (*TelemetryGetOutput, error) {
+	vars := map[string]interface{}{"deviceId": options.DeviceID}
+	if options.Start != nil {
+		vars["start"] = options.Start.Format(time.RFC3339)
+	}
+	if options.End != nil {
+		vars["end"] = options.End.Format(time.RFC3339)
+	}
+	if options.Grouping != nil {
+		vars["grouping"] = *options.Grouping
+	}
+
+	res := TelemetryGetOutput{}
+	if err := s.client.GraphQL.QueryWithContext(ctx, telemetryQuery, vars, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// TelemetryStreamOptions is the options for Stream.
+type TelemetryStreamOptions struct {
+	// The device to read, see ListDevices.
+	DeviceID string
+
+	// How often to poll for new readings. Defaults to 10 seconds, about how often a Home Mini reports.
+	PollInterval time.Duration
+
+	// Failed polls back off exponentially from PollInterval up to MaxBackoff. Defaults to 5 minutes.
+	MaxBackoff time.Duration
+
+	// Called with every failed poll, the stream keeps retrying regardless. Optional.
+	OnError func(err error)
+}
+
+// Stream polls a device and emits each new reading, oldest first, on the returned channel.
+// The channel is closed once the context is cancelled.
+func (s *TelemetryService) Stream(ctx context.Context, options *TelemetryStreamOptions) <-chan TelemetryReading {
+	interval := options.PollInterval
+	if interval <= 0 {
+		interval = defaultTelemetryPollInterval
+	}
+	maxBackoff := options.MaxBackoff
+	if maxBackoff < interval {
+		maxBackoff = defaultTelemetryMaxBackoff
+	}
+
+	ch := make(chan TelemetryReading)
+
+	go func() {
+		defer close(ch)
+
+		var last time.Time
+		wait := time.Duration(0)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+
+			opts := &TelemetryGetOptions{DeviceID: options.DeviceID}
+			if !last.IsZero() {
+				opts.Start = Time(last.Add(time.Second))
+				opts.End = Time(time.Now())
+			}
+
+			res, err := s.GetWithContext(ctx, opts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if options.OnError != nil {
+					options.OnError(err)
+				}
+				wait = backoff(wait, interval, maxBackoff)
+				continue
+			}
+			wait = interval
+
+			for _, reading := range sortedReadings(res.Readings) {
+				if !reading.ReadAt.After(last) {
+					continue
+				}
+				select {
+				case ch <- reading:
+					last = reading.ReadAt
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+// backoff doubles the previous wait, starting from min and never exceeding max.
+func backoff(previous, min, max time.Duration) time.Duration {
+	if previous < min {
+		return min
+	}
+	next := previous * 2
+	if next > max {
+		return max
+	}
+	return next
+}
+
+func sortedReadings(readings []TelemetryReading) []TelemetryReading {
+	sort.SliceStable(readings, func(i, j int) bool {
+		return readings[i].ReadAt.Before(readings[j].ReadAt)
+	})
+	return readings
+}
@@ -0,0 +1,82 @@
+package octopusenergy_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danopstech/octopusenergy"
+)
+
+func TestTelemetryStream(t *testing.T) {
+	var mu sync.Mutex
+	polls := 0
+	base := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(req.Query, "obtainKrakenToken") {
+			fmt.Fprint(w, `{"data":{"obtainKrakenToken":{"token":"jwt"}}}`)
+			return
+		}
+
+		mu.Lock()
+		polls++
+		n := polls
+		mu.Unlock()
+
+		switch n {
+		case 1:
+			fmt.Fprintf(w, `{"data":{"smartMeterTelemetry":[{"readAt":%q,"demand":"350.0","consumption":"1000.0"}]}}`, base.Format(time.RFC3339))
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			// the previous reading is repeated and must not be emitted twice
+			fmt.Fprintf(w, `{"data":{"smartMeterTelemetry":[{"readAt":%q,"demand":"350.0"},{"readAt":%q,"demand":"2400"}]}}`,
+				base.Format(time.RFC3339), base.Add(10*time.Second).Format(time.RFC3339))
+		}
+	}))
+	defer server.Close()
+
+	client := octopusenergy.NewClient(octopusenergy.NewConfig().
+		WithApiKey("sk_test").
+		WithEndpoint(server.URL),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var errs int
+	stream := client.Telemetry.Stream(ctx, &octopusenergy.TelemetryStreamOptions{
+		DeviceID:     "00-00-00-00-00-00-00-00",
+		PollInterval: 5 * time.Millisecond,
+		MaxBackoff:   20 * time.Millisecond,
+		OnError:      func(err error) { errs++ },
+	})
+
+	first, second := <-stream, <-stream
+	cancel()
+
+	if first.Demand != 350 || first.Consumption != 1000 || !first.ReadAt.Equal(base) {
+		t.Errorf("unexpected first reading %+v", first)
+	}
+	if second.Demand != 2400 || !second.ReadAt.Equal(base.Add(10*time.Second)) {
+		t.Errorf("unexpected second reading %+v", second)
+	}
+	if errs != 1 {
+		t.Errorf("expected 1 error to be reported, got %d", errs)
+	}
+
+	for range stream {
+		// drain until closed
+	}
+}
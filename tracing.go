@@ -0,0 +1,118 @@
+package octopusenergy
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/danopstech/octopusenergy"
+
+// Span attributes specific to this client, HTTP attributes follow the OpenTelemetry conventions.
+const (
+	attrService     = attribute.Key("octopusenergy.service")
+	attrMethod      = attribute.Key("octopusenergy.method")
+	attrFuelType    = attribute.Key("octopusenergy.fuel_type")
+	attrPage        = attribute.Key("octopusenergy.page")
+	attrAttempt     = attribute.Key("octopusenergy.attempt")
+	attrResultCount = attribute.Key("octopusenergy.result_count")
+)
+
+// tracer returns the client's tracer, from the global TracerProvider unless the config set one.
+func (c *Client) tracer() trace.Tracer {
+	tp := c.tracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(tracerName)
+}
+
+// tracing starts a client span for every request sent. It is below any added middleware, such as
+// Retry, so each attempt gets its own span.
+func (c *Client) tracing(next RequestHandler) RequestHandler {
+	return func(op Operation, req *http.Request, out interface{}) (*http.Response, error) {
+		attrs := []attribute.KeyValue{
+			attrService.String(op.Service),
+			attrMethod.String(op.Method),
+			semconv.HTTPMethodKey.String(req.Method),
+			semconv.HTTPRouteKey.String(op.PathTemplate),
+			semconv.HTTPURLKey.String(c.redactURL(req.URL)),
+			attrAttempt.Int(attempt(req.Context())),
+		}
+		if op.FuelType != "" {
+			attrs = append(attrs, attrFuelType.String(op.FuelType))
+		}
+		if page := pageNumber(req.URL); page > 0 {
+			attrs = append(attrs, attrPage.Int(page))
+		}
+
+		ctx, span := c.tracer().Start(req.Context(), op.Service+"."+op.Method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		res, err := next(op, req.WithContext(ctx), out)
+		if res != nil {
+			span.SetAttributes(semconv.HTTPStatusCodeKey.Int(res.StatusCode))
+		}
+		if err != nil {
+			return res, spanError(span, err)
+		}
+		if n, ok := resultCount(out); ok {
+			span.SetAttributes(attrResultCount.Int(n))
+		}
+		return res, nil
+	}
+}
+
+// startPagesSpan starts the parent span of a call fetching every page, the requests for each page are
+// its children.
+func (c *Client) startPagesSpan(ctx context.Context, op Operation) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attrService.String(op.Service),
+		attrMethod.String(op.Method),
+		semconv.HTTPRouteKey.String(op.PathTemplate),
+	}
+	if op.FuelType != "" {
+		attrs = append(attrs, attrFuelType.String(op.FuelType))
+	}
+	return c.tracer().Start(ctx, op.Service+"."+op.Method, trace.WithAttributes(attrs...))
+}
+
+// endPagesSpan records how many results were fetched across every page.
+func endPagesSpan(span trace.Span, results int) {
+	span.SetAttributes(attrResultCount.Int(results))
+}
+
+// spanError records err on span and returns it.
+func spanError(span trace.Span, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return err
+}
+
+// resultCount returns the length of the Results slice of a decoded list response.
+func resultCount(out interface{}) (int, bool) {
+	v := reflect.ValueOf(out)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0, false
+	}
+	results := v.FieldByName("Results")
+	if !results.IsValid() || results.Kind() != reflect.Slice {
+		return 0, false
+	}
+	return results.Len(), true
+}
@@ -0,0 +1,155 @@
+package octopusenergy_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/danopstech/octopusenergy"
+	"github.com/danopstech/octopusenergy/octopustest"
+)
+
+// recordingTracer is a minimal TracerProvider that keeps every span it starts.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	trace.Span
+	provider *recordingTracer
+	name     string
+	parent   *recordedSpan
+	attrs    map[attribute.Key]attribute.Value
+	status   codes.Code
+	ended    bool
+}
+
+type spanKey struct{}
+
+func (r *recordingTracer) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return r
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	s := &recordedSpan{
+		Span:     trace.SpanFromContext(ctx),
+		provider: r,
+		name:     name,
+		parent:   parent,
+		attrs:    map[attribute.Key]attribute.Value{},
+	}
+	s.SetAttributes(cfg.Attributes()...)
+
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.provider.mu.Lock()
+	defer s.provider.mu.Unlock()
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string)     { s.status = code }
+func (s *recordedSpan) RecordError(error, ...trace.EventOption) {}
+func (s *recordedSpan) End(...trace.SpanEndOption)              { s.ended = true }
+
+func TestTracing(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+
+	var charges []octopusenergy.TariffCharge
+	for i := 0; i < 1600; i++ {
+		from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * 30 * time.Minute)
+		charges = append(charges, octopusenergy.TariffCharge{ValueIncVat: 10, ValidFrom: from, ValidTo: from.Add(30 * time.Minute)})
+	}
+	srv.AddTariffCharges(octopusenergy.ProductCodeAgile180221, octopusenergy.FuelTypeElectricity, "E-1R-AGILE-18-02-21-H", octopusenergy.RateStandardUnit, charges...)
+
+	tracer := &recordingTracer{}
+	client := octopusenergy.NewClient(srv.ClientConfig().WithTracerProvider(tracer))
+
+	ctx, caller := tracer.Start(context.Background(), "sync")
+	_, err := client.TariffCharge.GetPagesWithContext(ctx, &octopusenergy.TariffChargesGetOptions{
+		ProductCode: octopusenergy.ProductCodeAgile180221,
+		TariffCode:  "E-1R-AGILE-18-02-21-H",
+		FuelType:    octopusenergy.FuelTypeElectricity,
+		Rate:        octopusenergy.RateStandardUnit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spans := tracer.spans
+	if len(spans) != 4 {
+		t.Fatalf("expected caller, pages and 2 request spans, got %d", len(spans))
+	}
+	pages := spans[1]
+	if pages.name != "TariffCharge.GetPages" || pages.parent != caller || !pages.ended {
+		t.Errorf("unexpected pages span %+v", pages)
+	}
+	if pages.attrs["octopusenergy.result_count"].AsInt64() != 1600 || pages.attrs["octopusenergy.fuel_type"].AsString() != "electricity" {
+		t.Errorf("unexpected pages span attributes %v", pages.attrs)
+	}
+
+	for i, s := range spans[2:] {
+		if s.name != "TariffCharge.Get" || s.parent != pages || !s.ended {
+			t.Errorf("unexpected request span %+v", s)
+		}
+		if s.attrs["http.status_code"].AsInt64() != 200 || s.attrs["http.route"].AsString() != "/v1/products/{product_code}/{fuel_type}-tariffs/{tariff_code}/{rate}/" {
+			t.Errorf("unexpected request span attributes %v", s.attrs)
+		}
+		if i == 1 && s.attrs["octopusenergy.page"].AsInt64() != 2 {
+			t.Errorf("expected second request for page 2, got %v", s.attrs["octopusenergy.page"])
+		}
+	}
+	if spans[2].attrs["octopusenergy.result_count"].AsInt64() != 1500 {
+		t.Errorf("expected 1500 results on the first page, got %v", spans[2].attrs["octopusenergy.result_count"])
+	}
+
+	tracer.spans = nil
+	if _, err := client.Account.GetWithContext(ctx, &octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"}); err == nil {
+		t.Fatal("expected unknown account to fail")
+	}
+	if s := tracer.spans[0]; s.status != codes.Error || s.attrs["http.status_code"].AsInt64() != 404 {
+		t.Errorf("expected failed span, got status %v attributes %v", s.status, s.attrs)
+	}
+}
+
+func TestTracingRetries(t *testing.T) {
+	srv := octopustest.NewServer()
+	defer srv.Close()
+	srv.AddAccount(octopusenergy.AccountGetOutput{Number: "A-AAAA1111"})
+	srv.InjectFault(octopustest.Fault{Path: "accounts", StatusCode: http.StatusServiceUnavailable, Times: 1})
+
+	tracer := &recordingTracer{}
+	client := octopusenergy.NewClient(srv.ClientConfig().WithTracerProvider(tracer).WithMiddleware(octopusenergy.Retry(3, time.Millisecond)))
+
+	if _, err := client.Account.Get(&octopusenergy.AccountGetOptions{AccountNumber: "A-AAAA1111"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// each attempt is its own span with its own outcome
+	if len(tracer.spans) != 2 {
+		t.Fatalf("expected a span for each attempt, got %d", len(tracer.spans))
+	}
+	failed, retried := tracer.spans[0], tracer.spans[1]
+	if failed.attrs["octopusenergy.attempt"].AsInt64() != 1 || failed.status != codes.Error || failed.attrs["http.status_code"].AsInt64() != 503 {
+		t.Errorf("unexpected first attempt, status %v attributes %v", failed.status, failed.attrs)
+	}
+	if retried.attrs["octopusenergy.attempt"].AsInt64() != 2 || retried.status == codes.Error || retried.attrs["http.status_code"].AsInt64() != 200 {
+		t.Errorf("unexpected retry, status %v attributes %v", retried.status, retried.attrs)
+	}
+}